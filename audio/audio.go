@@ -0,0 +1,117 @@
+// Package audio renders an audible fingerprint of a value: a short, deterministic sequence of tones
+// derived from the same hash as the value's avatar, e.g. for users who cannot compare avatars by eye.
+//
+// The package is experimental; the mapping from hashes to tones may still change.
+package audio
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// SampleRate is the sample rate of the generated WAV data in samples per second.
+const SampleRate = 22050
+
+// Tone sequence parameters.
+const (
+	toneCount     = 8
+	shortTone     = 150 * time.Millisecond
+	longTone      = 300 * time.Millisecond
+	pause         = 50 * time.Millisecond
+	fade          = 10 * time.Millisecond
+	amplitude     = 0.6 * math.MaxInt16
+	baseFrequency = 261.63 // C4
+)
+
+// pentatonic holds the semitone offsets of the major pentatonic scale, whose notes sound
+// pleasant in any order.
+var pentatonic = [...]int{0, 2, 4, 7, 9}
+
+// Tone is a single sine tone of the fingerprint.
+type Tone struct {
+	// Frequency is the pitch in Hz.
+	Frequency float64
+	Duration  time.Duration
+}
+
+// Tones returns the tone sequence for value. Every tone is derived from one byte of the hash
+// of value: its low bits select one of ten notes over two octaves, its high bit the duration.
+func Tones(value string) []Tone {
+	hash := sha256.Sum256([]byte(value))
+	tones := make([]Tone, toneCount)
+	for i := range tones {
+		b := hash[i]
+		note := int(b&0x7f) % (2 * len(pentatonic))
+		semitones := 12*(note/len(pentatonic)) + pentatonic[note%len(pentatonic)]
+		tones[i].Frequency = baseFrequency * math.Pow(2, float64(semitones)/12)
+		tones[i].Duration = shortTone
+		if b&0x80 != 0 {
+			tones[i].Duration = longTone
+		}
+	}
+	return tones
+}
+
+// WAV returns the tone sequence for value as a mono 16 bit PCM WAV file.
+func WAV(value string) []byte {
+	var samples []int16
+	for _, tone := range Tones(value) {
+		samples = appendTone(samples, tone)
+		samples = append(samples, make([]int16, sampleCount(pause))...)
+	}
+
+	var buf bytes.Buffer
+	dataLen := uint32(2 * len(samples))
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, 36+dataLen)
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, wavFormat{
+		Size:          16,
+		Format:        1, // PCM
+		Channels:      1,
+		SampleRate:    SampleRate,
+		ByteRate:      2 * SampleRate,
+		BlockAlign:    2,
+		BitsPerSample: 16,
+	})
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, dataLen)
+	binary.Write(&buf, binary.LittleEndian, samples)
+	return buf.Bytes()
+}
+
+// wavFormat is the fmt chunk of a WAV file without its ID.
+type wavFormat struct {
+	Size          uint32
+	Format        uint16
+	Channels      uint16
+	SampleRate    uint32
+	ByteRate      uint32
+	BlockAlign    uint16
+	BitsPerSample uint16
+}
+
+// appendTone appends the samples of tone to samples, fading in and out to avoid clicks.
+func appendTone(samples []int16, tone Tone) []int16 {
+	n, fadeLen := sampleCount(tone.Duration), sampleCount(fade)
+	for i := 0; i < n; i++ {
+		gain := 1.0
+		if i < fadeLen {
+			gain = float64(i) / float64(fadeLen)
+		} else if n-i < fadeLen {
+			gain = float64(n-i) / float64(fadeLen)
+		}
+		v := math.Sin(2 * math.Pi * tone.Frequency * float64(i) / SampleRate)
+		samples = append(samples, int16(amplitude*gain*v))
+	}
+	return samples
+}
+
+// sampleCount returns the number of samples covering d.
+func sampleCount(d time.Duration) int {
+	return int(d * SampleRate / time.Second)
+}
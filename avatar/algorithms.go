@@ -1,27 +1,96 @@
 package avatar
 
 import (
+	"fmt"
 	"image"
 	"image/color"
 	"math/rand"
+	"sync"
 )
 
-type algoFunc func(img *image.RGBA, size int, colorToFill color.Color, darkMode bool)
+// AlgorithmFunc draws the size by size pixel pattern of an avatar into img. Every pixel must be set
+// to either colorToFill or background, using r as the only source of randomness so avatars are
+// deterministic. Params are the parameters set with WithAlgorithmParams.
+type AlgorithmFunc func(img *image.RGBA, size int, r *rand.Rand, colorToFill, background color.Color, params any)
 
-var algoExecutorMap = map[Algorithm]algoFunc{
-	ALGORITHM_1: algorithm_one,
-	ALGORITHM_2: algorithm_two,
+// algorithm is a pattern algorithm together with the validation of its parameters.
+type algorithm struct {
+	run            AlgorithmFunc
+	validateParams func(params any) error
+	// builtin marks the algorithms GeneratorLite can compute without rendering.
+	builtin bool
 }
 
-func algorithm_one(img *image.RGBA, size int, colorToFill color.Color, darkMode bool) {
+// algoMu guards algoExecutorMap against concurrent registrations.
+var algoMu sync.RWMutex
+
+var algoExecutorMap = map[Algorithm]algorithm{
+	ALGORITHM_1: {algorithm_one, validateFillParams, true},
+	ALGORITHM_2: {algorithm_two, validateFillParams, true},
+	ALGORITHM_3: {algorithm_three, validateFillParams, true},
+}
+
+// RegisterAlgorithm makes a custom algorithm selectable with WithAlgorithm, replacing any algorithm
+// registered as algo before. The optional validate function checks the parameters set with
+// WithAlgorithmParams; without it any parameters are accepted. CheckSymmetry and
+// CheckPaletteMembership help to assert that custom algorithms behave like the built-in ones.
+func RegisterAlgorithm(algo Algorithm, fn AlgorithmFunc, validate func(params any) error) {
+	if validate == nil {
+		validate = func(any) error { return nil }
+	}
+	algoMu.Lock()
+	defer algoMu.Unlock()
+	algoExecutorMap[algo] = algorithm{run: fn, validateParams: validate}
+}
+
+// lookupAlgorithm returns the algorithm registered as algo.
+func lookupAlgorithm(algo Algorithm) (algorithm, bool) {
+	algoMu.RLock()
+	defer algoMu.RUnlock()
+	a, ok := algoExecutorMap[algo]
+	return a, ok
+}
+
+// defaultFillBias is the probability of a cell being filled when no FillParams are given.
+const defaultFillBias = 0.5
+
+// FillParams tunes the random fill of the built-in algorithms.
+type FillParams struct {
+	// Bias is the probability, between 0 and 1, of a cell being filled.
+	Bias float64
+}
+
+func validateFillParams(params any) error {
+	switch p := params.(type) {
+	case nil:
+		return nil
+	case FillParams:
+		if p.Bias < 0 || p.Bias > 1 {
+			return fmt.Errorf("%w: fill bias %g is not between 0 and 1", ErrInvalidAlgorithmParams, p.Bias)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %T is not FillParams", ErrInvalidAlgorithmParams, params)
+}
+
+// fillBias returns the fill probability set by FillParams.
+func fillBias(params any) float64 {
+	if p, ok := params.(FillParams); ok {
+		return p.Bias
+	}
+	return defaultFillBias
+}
+
+func algorithm_one(img *image.RGBA, size int, r *rand.Rand, colorToFill, background color.Color, params any) {
+	bias := fillBias(params)
 	bounds := img.Bounds()
 	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
 		for x := bounds.Min.X; x < bounds.Max.X; x++ {
 			if y <= int(size)/2 {
-				if rand.Float64() < 0.5 {
+				if r.Float64() < bias {
 					img.Set(y, x, colorToFill)
 				} else {
-					img.Set(y, x, getBackgroundColor(darkMode))
+					img.Set(y, x, background)
 				}
 			} else {
 				img.Set(y, x, img.At(int(size)-y-1, x))
@@ -30,15 +99,16 @@ func algorithm_one(img *image.RGBA, size int, colorToFill color.Color, darkMode
 	}
 }
 
-func algorithm_two(img *image.RGBA, size int, colorToFill color.Color, darkMode bool) {
+func algorithm_two(img *image.RGBA, size int, r *rand.Rand, colorToFill, background color.Color, params any) {
+	bias := fillBias(params)
 	bounds := img.Bounds()
 	for y := bounds.Max.Y; y >= 0; y-- {
 		for x := bounds.Min.X; x < bounds.Max.X; x++ {
 			if x <= int(size)/2 {
-				if rand.Float64() < 0.5 {
+				if r.Float64() < bias {
 					img.Set(x, y, colorToFill)
 				} else {
-					img.Set(x, y, getBackgroundColor(darkMode))
+					img.Set(x, y, background)
 				}
 			} else {
 				img.Set(x, y, img.At((int(size))-x-1, y))
@@ -47,6 +117,20 @@ func algorithm_two(img *image.RGBA, size int, colorToFill color.Color, darkMode
 	}
 }
 
+func algorithm_three(img *image.RGBA, size int, r *rand.Rand, colorToFill, background color.Color, params any) {
+	bias := fillBias(params)
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if r.Float64() < bias {
+				img.Set(x, y, colorToFill)
+			} else {
+				img.Set(x, y, background)
+			}
+		}
+	}
+}
+
 func getBackgroundColor(darkMode bool) color.Color {
 	if darkMode {
 		return color.Black
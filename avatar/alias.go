@@ -0,0 +1,114 @@
+package avatar
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/bugcacher/godenticon/internal/store"
+)
+
+// manifestMu serializes updates of alias manifests within the process.
+var manifestMu sync.Mutex
+
+// WithAlias makes content addressed files, see WithContentAddressedNames, also reachable by value:
+// ALIAS_SYMLINK creates a symbolic link named after the value and the extension of the format, e.g.
+// "jane@example.com.png", ALIAS_MANIFEST records the value and file name in the aliases.json file
+// of the output directory.
+// Without content addressed names the option has no effect.
+func WithAlias(alias Alias) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.alias = alias
+	}
+}
+
+// writeAlias makes the saved file with the given name reachable by the value of the avatar.
+func (av *Avatar) writeAlias(fileName string) error {
+	if !av.contentAddressed {
+		return nil
+	}
+	switch av.alias {
+	case ALIAS_SYMLINK:
+		return av.writeSymlink(fileName)
+	case ALIAS_MANIFEST:
+		return av.writeManifest(fileName)
+	}
+	return nil
+}
+
+// writeSymlink points the symbolic link named after the value to fileName, replacing it atomically.
+func (av *Avatar) writeSymlink(fileName string) error {
+	link := filepath.Join(av.path, store.FileName(av.value, av.format.Extension()))
+	tmp, err := os.CreateTemp(av.path, ".alias-*")
+	if err != nil {
+		return av.wrapErr(ErrWrite, "create alias "+link, err)
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+	if err := os.Symlink(fileName, tmp.Name()); err != nil {
+		return av.wrapErr(ErrWrite, "create alias "+link, err)
+	}
+	if err := os.Rename(tmp.Name(), link); err != nil {
+		os.Remove(tmp.Name())
+		return av.wrapErr(ErrWrite, "create alias "+link, err)
+	}
+	return nil
+}
+
+// writeManifest records the value and fileName in the alias manifest of the output directory.
+func (av *Avatar) writeManifest(fileName string) error {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	path := filepath.Join(av.path, aliasManifestName)
+	aliases, err := ReadAliasManifest(av.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return av.wrapErr(ErrWrite, "read alias manifest "+path, err)
+	}
+	if aliases == nil {
+		aliases = make(map[string]string)
+	}
+	if aliases[av.value] == fileName {
+		return nil
+	}
+	aliases[av.value] = fileName
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return av.wrapErr(ErrWrite, "encode alias manifest", err)
+	}
+	tmp, err := os.CreateTemp(av.path, ".aliases-*")
+	if err != nil {
+		return av.wrapErr(ErrWrite, "write alias manifest "+path, err)
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return av.wrapErr(ErrWrite, "write alias manifest "+path, err)
+	}
+	return nil
+}
+
+// ReadAliasManifest returns the aliases recorded with ALIAS_MANIFEST in dir, mapping values to file names.
+func ReadAliasManifest(dir string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, aliasManifestName))
+	if err != nil {
+		return nil, err
+	}
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
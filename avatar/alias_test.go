@@ -0,0 +1,24 @@
+package avatar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSymlinkAlias(t *testing.T) {
+	dir := t.TempDir()
+	result, err := New("jane@example.com", WithOutputDir(dir), WithFormat(FORMAT_SVG),
+		WithContentAddressedNames(), WithAlias(ALIAS_SYMLINK)).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := os.Readlink(filepath.Join(dir, "jane@example.com.svg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != filepath.Base(result.FilePath) || !strings.HasSuffix(target, ".svg") {
+		t.Errorf("alias points to %q, want %q", target, filepath.Base(result.FilePath))
+	}
+}
@@ -0,0 +1,54 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// AuditEvent describes a generated avatar for audit logs. It identifies the value by a hash prefix,
+// so logs can correlate generations without storing the values themselves.
+type AuditEvent struct {
+	Time time.Time
+	// ValueHash is the hex encoded first 8 bytes of the SHA-256 of the value.
+	ValueHash string
+	// Style identifies the options the avatar was rendered with, as in manifests.
+	Style  string
+	Output Output
+	Format Format
+	// Path is the path of the saved file, empty unless Output is OUTPUT_FILE.
+	Path string
+	// Err is the error encoding or saving the avatar failed with, nil on success.
+	Err error
+}
+
+// WithAuditHook calls hook after every avatar is encoded, saved or returned as raw pixels, and after
+// every failure to, so regulated environments can log what was generated without wrapping the library.
+// Avatars a Generator serves from its cache are not generated again and emit no event. The hook is
+// called on the generating goroutine and must be safe for concurrent use if avatars are generated
+// concurrently.
+func WithAuditHook(hook func(event AuditEvent)) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.auditHook = hook
+	}
+}
+
+// audit passes the event of the generated result, or of err, to the audit hook if one is set.
+func (av *Avatar) audit(result *AvatarResult, err error) {
+	if av.auditHook == nil {
+		return
+	}
+	hash := sha256.Sum256([]byte(av.value))
+	event := AuditEvent{
+		Time:      time.Now(),
+		ValueHash: hex.EncodeToString(hash[:8]),
+		Style:     styleFingerprint(av.styleKey(), av.theme),
+		Output:    av.outputType,
+		Format:    av.format,
+		Err:       err,
+	}
+	if result != nil {
+		event.Path = result.FilePath
+	}
+	av.auditHook(event)
+}
@@ -0,0 +1,68 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+)
+
+// Dimensions of author avatars in blame and annotation gutters.
+const (
+	minAuthorIconSize = 16
+	maxAuthorIconSize = 24
+)
+
+// GenerateAuthorIcons returns PNG avatars with a dimension of 16 to 24 pixels for the author emails
+// of a commit log, e.g. for the blame gutters of git web UIs. Emails are compared case-insensitively
+// and without surrounding whitespace, so every author is rendered and encoded once and all spellings
+// of an email map to the same data. The icons are encoded with the best compression, as paletted
+// PNGs unless the options add too many colors. Emails that fail to render are left out and their
+// errors are returned together.
+func GenerateAuthorIcons(emails []string, size uint, opts ...CreateOption) (map[string][]byte, error) {
+	if size < minAuthorIconSize || size > maxAuthorIconSize {
+		return nil, New("").wrapErr(ErrInvalidOption, "check author icon size",
+			fmt.Errorf("%w: %dpx, expected %d to %dpx", ErrInvalidDimension, size, minAuthorIconSize, maxAuthorIconSize))
+	}
+	opts = append(opts[:len(opts):len(opts)], WithDimension(size))
+
+	icons := make(map[string][]byte, len(emails))
+	rendered := make(map[string][]byte)
+	var errs []error
+	for _, email := range emails {
+		author := strings.ToLower(strings.TrimSpace(email))
+		data, ok := rendered[author]
+		if !ok {
+			var err error
+			if data, err = New(author, opts...).encodeAuthorIcon(); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			rendered[author] = data
+		}
+		icons[email] = data
+	}
+	return icons, errors.Join(errs...)
+}
+
+// encodeAuthorIcon renders the avatar and encodes it as small as possible without losing colors.
+func (av *Avatar) encodeAuthorIcon() ([]byte, error) {
+	if err := av.validate(); err != nil {
+		return nil, err
+	}
+	if err := av.render(); err != nil {
+		return nil, err
+	}
+	var img image.Image = av.image
+	// A palette with room to spare holds every color of the image.
+	if paletted := quantize(av.image, 256); len(paletted.Palette) < 256 {
+		img = paletted
+	}
+	var buf bytes.Buffer
+	if err := encodePNG(&buf, img, av.density(), png.BestCompression); err != nil {
+		return nil, av.wrapErr(ErrEncode, "encode png", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -4,15 +4,21 @@ package avatar
 
 import (
 	"bytes"
+	"context"
+	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
+	"fmt"
 	"image"
 	"image/color"
-	"image/png"
-	"log"
+	"io"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"golang.org/x/image/draw"
 )
@@ -26,8 +32,93 @@ type Avatar struct {
 	darkMode     bool
 	pixelPattern PixelPattern
 	algo         Algorithm
+	prng         PRNG
+	algoParams   any
+	// entropySplit limits the hash bits feeding the pattern and the colors, nil uses the whole hash for both.
+	entropySplit *entropySplit
 	outputType   Output
-	image        *image.RGBA
+	format       Format
+	palette      []color.Color
+	brandColor   color.Color
+	fillColor    color.Color
+	colorSpace   ColorSpace
+	// colorBands holds the lightness and chroma bands of colors derived in a perceptual color space.
+	colorBands *colorBands
+	// lightnessRange and saturationRange clamp derived colors, nil leaves them unchanged.
+	lightnessRange  *[2]float64
+	saturationRange *[2]float64
+	twoTone         TwoTone
+	themeProvider   func(time.Time) Theme
+	// theme is the theme resolved for the current render, nil without a theme provider.
+	theme *Theme
+	// contentAddressed names saved files by the hash of their content.
+	contentAddressed bool
+	alias            Alias
+	manifest         *Manifest
+	snapToGrid       bool
+	supersampling    int
+	blurHash         bool
+	lqipSize         int
+	svgVariables     bool
+	svgTitle         string
+	svgDesc          string
+	// formatFallback encodes as PNG if the format has no encoder, calling formatWarning first.
+	formatFallback bool
+	formatWarning  func(err error)
+	// sanitizeValue applies SanitizeValue to the values of group members, see WithValueSanitization.
+	sanitizeValue bool
+	auditHook     func(event AuditEvent)
+	// ttl is the time after which files of a CachingFS expire, zero keeps them forever.
+	ttl   time.Duration
+	quota *diskQuota
+	// byteCache shares the avatars of Generators, see WithByteCache.
+	byteCache    ByteCache
+	byteCacheTTL time.Duration
+	// cacheEntries and cacheBytes limit the in-memory cache of Generators, see WithMemoryCacheLimit.
+	cacheEntries int
+	cacheBytes   int64
+	// limiter holds a token for every running Generate call, see WithMaxParallelism.
+	limiter chan struct{}
+	// deadlinePolicy applies when less than deadlineMargin is left until the deadline of GenerateContext.
+	deadlinePolicy DeadlinePolicy
+	deadlineMargin time.Duration
+	// profileCtx carries the pprof labels of the current generation, nil outside of GenerateContext.
+	profileCtx context.Context
+	timings    Timings
+	// mappedCanvas renders banners into a memory-mapped file in mappedCanvasDir, see WithMappedCanvas.
+	mappedCanvas    bool
+	mappedCanvasDir string
+	// scanlines replaces image for composites encoded row by row, see encodeScanlines.
+	scanlines *scanlines
+	// checksum selects the checksum files written beside saved avatars, see WithChecksumSidecar.
+	checksum         Checksum
+	provenanceKey    ed25519.PrivateKey
+	emptyValuePolicy EmptyValuePolicy
+	jpegQuality      int
+	// overrides replace the identicons of reserved values, see WithOverrides.
+	overrides     map[string]OverrideSpec
+	overridesKey  string
+	overridden    bool
+	overrideImage image.Image
+	// pattern is the unscaled pixel pattern of the last render, from which SVG output is drawn.
+	pattern *image.RGBA
+	// canvases provides the output canvas, nil allocates a new one.
+	canvases *canvasPool
+	// gradientBackground renders the pattern in black or white over a hash derived gradient.
+	gradientBackground bool
+	placeholderPhoto   bool
+	cellBevel          float64
+	maskPath           []Point
+	safeArea           float64
+	scaleFactor        float64
+	dpi                int
+	maxBytes           int
+	progressRing       *progressRing
+	countBadge         *countBadge
+	locale             string
+	image              *image.RGBA
+	// err records an invalid option, it is returned by Generate.
+	err error
 }
 
 // AvatarResult contains the result of an avatar generation process.
@@ -38,6 +129,23 @@ type AvatarResult struct {
 	// Buffer contains the generated avatar image as a byte buffer.
 	// Buffer will be nil if the OutputType is OutputFile.
 	Buffer *bytes.Buffer
+	// Image contains the rendered pixels without any encoding.
+	// Image is only set if the OutputType is OUTPUT_RAW.
+	Image *image.RGBA
+	// BlurHash contains the BlurHash of the image, see WithBlurHash.
+	// BlurHash will be empty unless WithBlurHash is set.
+	BlurHash string
+	// LQIP contains the low quality image placeholder as PNG, see WithLQIP.
+	// LQIP will be nil unless WithLQIP is set.
+	LQIP []byte
+	// Timings contains the time spent in each phase of the generation.
+	Timings Timings
+	// Provenance contains the signed provenance of the avatar, see WithProvenance.
+	// Provenance will be nil unless WithProvenance is set and the avatar is encoded.
+	Provenance []byte
+
+	// pixels holds the rendered image for DominantColors.
+	pixels *image.RGBA
 }
 
 // New creates and returns a new Avatar object with the specified value and options.
@@ -48,6 +156,11 @@ func New(value string, opts ...CreateOption) *Avatar {
 		algo:         ALGORITHM_1,
 		outputType:   OUTPUT_FILE,
 		dimension:    100,
+		safeArea:     100,
+		scaleFactor:  1,
+		locale:       defaultLocale,
+		cacheEntries: defaultCacheEntries,
+		cacheBytes:   defaultCacheBytes,
 	}
 	for _, opt := range opts {
 		opt(avatar)
@@ -55,6 +168,25 @@ func New(value string, opts ...CreateOption) *Avatar {
 	return avatar
 }
 
+// NewFromUint64 creates and returns a new Avatar object for a numeric ID.
+// The ID is hashed in its canonical decimal form, so the result does not depend on the platform byte order
+// and matches New(strconv.FormatUint(id, 10)), while inputs like "042" cannot alias it.
+func NewFromUint64(id uint64, opts ...CreateOption) *Avatar {
+	return New(strconv.FormatUint(id, 10), opts...)
+}
+
+// Clone returns a copy of the avatar configuration with the given options applied on top,
+// so per-request variants can be derived from a base style without re-specifying it.
+// The avatar itself is not modified.
+func (av *Avatar) Clone(opts ...CreateOption) *Avatar {
+	clone := *av
+	clone.image, clone.pattern, clone.theme = nil, nil, nil
+	for _, opt := range opts {
+		opt(&clone)
+	}
+	return &clone
+}
+
 // WithPixelPattern sets the pixel pattern size of the generated avatar.
 // Pixel pattern size defines the base image pixel pattern of the avatar.
 // For example, PIXEL_PATTERN_5 creates an avatar with a 5x5 pixel pattern.
@@ -65,13 +197,16 @@ func WithPixelPattern(pixelPattern PixelPattern) func(a *Avatar) {
 	}
 }
 
-// WithOutputPath sets the directory path for the generated avatar image file.
-// This option is ignored if the output type is OutputBuffer.
+// WithOutputDir sets the directory path for the generated avatar image file, creating it if needed.
+// This option is ignored if the output type is OutputBuffer. Generate returns ErrInvalidOutputDir
+// if the directory cannot be created.
 func WithOutputDir(path string) func(a *Avatar) {
-	if err := ensurePath(path); err != nil {
-		log.Default().Fatal("Invalid path given")
-	}
+	err := ensurePath(path)
 	return func(a *Avatar) {
+		if err != nil {
+			a.err = fmt.Errorf("%w: %s: %w", ErrInvalidOutputDir, path, err)
+			return
+		}
 		a.path = path
 	}
 }
@@ -83,6 +218,24 @@ func WithAlgorithm(algo Algorithm) func(a *Avatar) {
 	}
 }
 
+// WithAlgorithmParams sets algorithm specific parameters, e.g. FillParams for the built-in algorithms.
+// Generate returns ErrInvalidAlgorithmParams if the parameters do not suit the selected algorithm.
+func WithAlgorithmParams(params any) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.algoParams = params
+	}
+}
+
+// WithPRNG sets the pseudo random number generator the algorithms draw the pattern with.
+// The default PRNG_MATH_RAND is a math/rand source seeded per avatar from 32 bits of the value hash.
+// The other generators are seeded from the full value hash, are faster, and produce sequences that
+// are guaranteed not to change between releases.
+func WithPRNG(prng PRNG) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.prng = prng
+	}
+}
+
 // WithDarkMode enables dark mode for the avatar, setting the background color to black.
 func WithDarkMode() func(a *Avatar) {
 	return func(a *Avatar) {
@@ -90,14 +243,59 @@ func WithDarkMode() func(a *Avatar) {
 	}
 }
 
+// WithBrandColor keeps every avatar on the hue of the given brand color.
+// The value hash only varies the saturation and lightness of the fill color, so avatars stay
+// individually distinguishable. The brand color takes precedence over palettes.
+func WithBrandColor(c color.Color) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.brandColor = c
+	}
+}
+
+// WithFillColor fills the pattern with exactly the given color, e.g. one assigned by AssignDistinctColors.
+// It takes precedence over brand colors and palettes.
+func WithFillColor(c color.Color) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.fillColor = c
+	}
+}
+
+// WithScaleFactor multiplies the output dimension, e.g. by 2 for retina displays.
+// The physical size embedded by WithDPI stays the one of the unscaled dimension.
+func WithScaleFactor(f float64) func(a *Avatar) {
+	return func(a *Avatar) {
+		if f > 0 {
+			a.scaleFactor = f
+		}
+	}
+}
+
+// WithDPI embeds the physical pixel density of the unscaled avatar in PNG and JPEG output (the pHYs
+// chunk and the JFIF header), so design and print tools display it at the intended size.
+// Other formats carry no density.
+func WithDPI(dpi int) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.dpi = dpi
+	}
+}
+
 // WithOutputType sets the output type for the generated avatar.
-// The avatar can be saved to a file or stored in a buffer.
+// The avatar can be saved to a file, stored in a buffer or returned as raw pixels.
+// Raw pixels are not encoded, so WithMaxBytes and WithDPI have no effect on them.
 func WithOutputType(outputType Output) func(a *Avatar) {
 	return func(a *Avatar) {
 		a.outputType = outputType
 	}
 }
 
+// WithContentAddressedNames names saved files by the SHA-256 hash of their content, e.g.
+// "sha256-<hex>.png", so they can be served as immutable assets and identical avatars are stored once.
+func WithContentAddressedNames() func(a *Avatar) {
+	return func(a *Avatar) {
+		a.contentAddressed = true
+	}
+}
+
 // WithDimension sets the dimensions (height and width) of the generated avatar.
 func WithDimension(dimension uint) func(a *Avatar) {
 	return func(a *Avatar) {
@@ -107,65 +305,494 @@ func WithDimension(dimension uint) func(a *Avatar) {
 
 // Generate creates a unique avatar for the given value based on the Avatar configuration.
 func (av *Avatar) Generate() (*AvatarResult, error) {
-	hash := sha256.Sum256([]byte(av.value))
-	seed := binary.BigEndian.Uint32(hash[:])
-	rand.Seed(int64(seed))
+	return av.GenerateContext(context.Background())
+}
 
-	r := uint8(uint64(byteSum(hash[0:8])) % 256)
-	g := uint8(uint64(byteSum(hash[8:16])) % 256)
-	b := uint8(uint64(byteSum(hash[16:24])) % 256)
-	a := uint8(uint64(byteSum(hash[24:32])) % 256)
-	avatarColor := color.RGBA{r, g, b, a}
+// GenerateContext is like Generate, but returns the error of ctx if it is done before rendering
+// starts, e.g. while waiting for the limit of WithMaxParallelism, and follows the policy of
+// WithDeadlinePolicy when ctx is close to its deadline.
+func (av *Avatar) GenerateContext(ctx context.Context) (*AvatarResult, error) {
+	rendered, release, err := av.renderContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return rendered.output()
+}
+
+// GenerateTo encodes the avatar straight into w, e.g. an HTTP response or a gzip writer, instead of
+// a file or buffer. The output type is ignored and WithBlurHash, WithLQIP and WithManifest have no
+// effect, as they need the whole encoded avatar. With WithProvenance the avatar is encoded in full
+// before it is written and only PNGs carry the provenance. Returned errors of w wrap ErrEncode.
+func (av *Avatar) GenerateTo(w io.Writer) error {
+	rendered, release, err := av.renderContext(context.Background())
+	if err != nil {
+		return err
+	}
+	defer release()
+	if rendered.provenanceKey != nil {
+		// The provenance covers the whole encoded avatar.
+		var data []byte
+		if data, _, err = rendered.encodeSigned(); err == nil {
+			if _, err = w.Write(data); err != nil {
+				err = rendered.wrapErr(ErrEncode, "write "+rendered.format.String(), err)
+			}
+		}
+		rendered.audit(nil, err)
+		return err
+	}
+	rendered.phase("encode", &rendered.timings.Encode, func() {
+		err = rendered.encode(w)
+	})
+	if err != nil {
+		err = rendered.wrapErr(ErrEncode, "encode "+rendered.format.String(), err)
+		rendered.audit(nil, err)
+		return err
+	}
+	rendered.audit(nil, nil)
+	return nil
+}
+
+// renderContext validates and renders the avatar, following the policy of WithDeadlinePolicy, and
+// returns the rendered copy of av, downgraded if the policy applied. The returned release
+// function frees the slot of WithMaxParallelism once the rendered avatar is written.
+func (av *Avatar) renderContext(ctx context.Context) (rendered *Avatar, release func(), err error) {
+	if rendered, err = av.prepare(); err != nil {
+		return nil, nil, err
+	}
+	release = func() {}
+	if av.limiter != nil {
+		select {
+		case av.limiter <- struct{}{}:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+		release = func() { <-av.limiter }
+	}
+	rendered.profileCtx = ctx
+	if nearDeadline(ctx, av.deadlineMargin) {
+		if av.deadlinePolicy == DEADLINE_DOWNGRADE {
+			rendered.downgrade()
+		} else if err := ctx.Err(); err != nil {
+			release()
+			return nil, nil, err
+		}
+	}
+	if err := rendered.render(); err != nil {
+		release()
+		return nil, nil, err
+	}
+	return rendered, release, nil
+}
+
+// prepare returns a validated copy of the avatar that holds the state of a single render, such as
+// the rendered image, the timings and the applied override. Renders only write to the copy, so an
+// Avatar is never modified after its options are applied and can be generated from concurrent
+// goroutines. Returned errors wrap ErrInvalidOption.
+func (av *Avatar) prepare() (*Avatar, error) {
+	prepared := *av
+	prepared.image, prepared.pattern, prepared.scanlines = nil, nil, nil
+	prepared.profileCtx, prepared.timings = nil, Timings{}
+	if err := prepared.validate(); err != nil {
+		return nil, err
+	}
+	return &prepared, nil
+}
+
+// render draws the avatar for the configured value into av.image, scaled to the configured dimension.
+func (av *Avatar) render() error {
+	var hash [sha256.Size]byte
+	av.phase("hash", &av.timings.Hash, func() {
+		hash = sha256.Sum256([]byte(av.value))
+	})
+	var background color.Color
+	var err error
+	av.phase("pattern", &av.timings.Pattern, func() {
+		background, err = av.drawPattern(hash)
+	})
+	if err != nil {
+		return err
+	}
+	av.phase("render", &av.timings.Render, func() {
+		av.compose(hash, background)
+	})
+	return nil
+}
+
+// drawPattern draws the unscaled pixel pattern into av.image and returns its background color.
+func (av *Avatar) drawPattern(hash [sha256.Size]byte) (color.Color, error) {
+	return av.drawPatternFrom(hash, nil)
+}
+
+// drawPatternFrom draws the pixel pattern like drawPattern, with the random source wrapped by wrap unless it is nil.
+func (av *Avatar) drawPatternFrom(hash [sha256.Size]byte, wrap func(rand.Source) rand.Source) (color.Color, error) {
+	av.resolveTheme()
+	patternHash := av.entropySplit.patternHash(hash)
 
 	height, width := av.pixelPattern, av.pixelPattern
 	av.image = image.NewRGBA(image.Rect(0, 0, int(height), int(width)))
 
-	av.applyAlgorithm(avatarColor, av.darkMode)
+	fill, background := av.patternColors(hash)
+	var src rand.Source
+	if av.prng == PRNG_MATH_RAND {
+		src = mathRandSource(patternHash)
+		defer mathRandSources.Put(src)
+	} else {
+		src = newPRNGSource(av.prng, patternHash)
+	}
+	if wrap != nil {
+		src = wrap(src)
+	}
+	err := av.applyAlgorithm(rand.New(src), fill, background)
+	av.pattern = av.image
+	return background, err
+}
 
-	av.scaleImage()
+// patternColors returns the colors of filled and empty cells of the pixel pattern.
+func (av *Avatar) patternColors(hash [sha256.Size]byte) (fill, background color.Color) {
+	if av.gradientBackground {
+		return av.gradientForeground(hash), color.Transparent
+	}
+	return av.color(hash), getBackgroundColor(av.darkMode)
+}
+
+// PatternImage renders the unscaled pixel pattern of the avatar, one pixel per cell,
+// without any of the effects applied at the output dimension.
+func (av *Avatar) PatternImage() (*image.RGBA, error) {
+	av, err := av.prepare()
+	if err != nil {
+		return nil, err
+	}
+	return av.patternImage()
+}
 
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, av.image); err != nil {
+// patternImage draws the unscaled pixel pattern into av.image and returns it.
+func (av *Avatar) patternImage() (*image.RGBA, error) {
+	if _, err := av.drawPattern(sha256.Sum256([]byte(av.value))); err != nil {
 		return nil, err
 	}
+	return av.image, nil
+}
 
+// compose scales the pixel pattern in av.image to the output dimension and applies the configured effects,
+// at a multiple of the output dimension followed by downsampling if WithSupersampling is set.
+func (av *Avatar) compose(hash [sha256.Size]byte, background color.Color) {
+	if av.supersampling <= 1 {
+		av.composeEffects(hash, background)
+		return
+	}
+	size, scaleFactor := av.outputDimension(), av.scaleFactor
+	av.scaleFactor *= float64(av.supersampling)
+	av.composeEffects(hash, background)
+	av.scaleFactor = scaleFactor
+	downsampled := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(downsampled, downsampled.Bounds(), av.image, av.image.Bounds(), draw.Src, nil)
+	av.image = downsampled
+}
+
+// composeEffects scales the pixel pattern in av.image to the output dimension and applies the configured effects.
+func (av *Avatar) composeEffects(hash [sha256.Size]byte, background color.Color) {
+	if av.overrideImage != nil {
+		av.drawOverrideImage()
+	} else if av.placeholderPhoto {
+		av.drawPlaceholderPhoto(hash)
+	} else {
+		av.composePattern(hash, background)
+	}
+	if len(av.maskPath) > 0 {
+		av.applyMask()
+	}
+	av.drawOverlay()
+	av.decorate()
+}
+
+// composePattern scales the pixel pattern in av.image to the output dimension and applies the pattern effects.
+func (av *Avatar) composePattern(hash [sha256.Size]byte, background color.Color) {
+	if av.twoTone != TWO_TONE_NONE {
+		av.applyTwoTone(hash, background)
+	}
+	pattern := av.image
+	av.scaleImage(background)
+	if av.cellBevel > 0 {
+		av.applyCellBevel(pattern, background)
+	}
+	if av.gradientBackground {
+		av.drawGradientBackground(hash)
+	}
+}
+
+// color derives the fill color of the avatar from the value hash.
+func (av *Avatar) color(hash [sha256.Size]byte) color.Color {
+	if av.fillColor != nil {
+		return av.fillColor
+	}
+	return av.clampColor(av.derivedColor(av.entropySplit.colorHash(hash)))
+}
+
+// derivedColor returns the fill color derived from the hash by the brand color, palette or color space.
+func (av *Avatar) derivedColor(hash [sha256.Size]byte) color.Color {
+	if av.brandColor != nil {
+		h, _, _ := toHSL(av.brandColor)
+		return fromHSL(h, brandMinSaturation+hashFraction(hash[16:20])*brandSaturationRange,
+			brandMinLightness+hashFraction(hash[20:24])*brandLightnessRange)
+	}
+	if palette := av.activePalette(); len(palette) > 0 {
+		return palette[binary.BigEndian.Uint64(hash[24:32])%uint64(len(palette))]
+	}
+	if av.colorSpace != COLOR_SPACE_RGB {
+		return av.perceptualColor(hash)
+	}
+	r := uint8(uint64(byteSum(hash[0:8])) % 256)
+	g := uint8(uint64(byteSum(hash[8:16])) % 256)
+	b := uint8(uint64(byteSum(hash[16:24])) % 256)
+	a := uint8(uint64(byteSum(hash[24:32])) % 256)
+	return color.RGBA{r, g, b, a}
+}
+
+// output writes the rendered image to the configured output type and reports it to the audit hook.
+func (av *Avatar) output() (*AvatarResult, error) {
+	result, err := av.writeOutput()
+	if result != nil {
+		result.Timings = av.timings
+	}
+	av.audit(result, err)
+	return result, err
+}
+
+// writeOutput writes the rendered image to the configured output type.
+func (av *Avatar) writeOutput() (*AvatarResult, error) {
+	result, err := av.placeholders()
+	if err != nil {
+		return nil, err
+	}
 	switch av.outputType {
 	case OUTPUT_FILE:
-		filePath, err := av.saveToFile()
+		data, provenance, err := av.encodeSigned()
 		if err != nil {
 			return nil, err
 		}
-		return &AvatarResult{FilePath: filePath}, nil
+		var filePath string
+		av.phase("io", &av.timings.IO, func() {
+			filePath, err = av.saveToFile(data)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if provenance != nil && av.format != FORMAT_PNG {
+			if err := writeProvenanceSidecar(filePath, provenance); err != nil {
+				return nil, av.wrapErr(ErrWrite, "write provenance of "+filePath, err)
+			}
+		}
+		if err := av.manifestEntry(av.manifest, filePath, data); err != nil {
+			return nil, err
+		}
+		result.FilePath, result.Provenance = filePath, provenance
+		return result, nil
 	case OUTPUT_BUFFER:
-		return &AvatarResult{Buffer: &buf}, nil
+		data, provenance, err := av.encodeSigned()
+		if err != nil {
+			return nil, err
+		}
+		if err := av.manifestEntry(av.manifest, "", data); err != nil {
+			return nil, err
+		}
+		result.Buffer, result.Provenance = bytes.NewBuffer(data), provenance
+		return result, nil
+	case OUTPUT_RAW:
+		result.Image = av.image
+		return result, nil
 	}
 
-	return nil, ErrUnknownOutputType
+	return nil, av.wrapErr(ErrInvalidOption, "check output type", ErrUnknownOutputType)
+}
+
+// placeholders returns a result for the rendered image holding the placeholders set by WithBlurHash and WithLQIP.
+func (av *Avatar) placeholders() (*AvatarResult, error) {
+	result := &AvatarResult{pixels: av.image}
+	if av.blurHash {
+		result.BlurHash = computeBlurHash(av.image, blurHashComponents, blurHashComponents)
+	}
+	if av.lqipSize > 0 {
+		data, err := av.encodeLQIP()
+		if err != nil {
+			return nil, err
+		}
+		result.LQIP = data
+	}
+	return result, nil
 }
 
 // applyAlgorithm applies the selected algorithm to generate the avatar's pixel pattern.
-func (av *Avatar) applyAlgorithm(colorToFill color.Color, darkMode bool) {
-	algoFunc := algoExecutorMap[av.algo]
-	algoFunc(av.image, int(av.pixelPattern), colorToFill, darkMode)
+// A panicking algorithm is recovered and reported as an error wrapping ErrAlgorithmPanic.
+func (av *Avatar) applyAlgorithm(r *rand.Rand, colorToFill, background color.Color) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = av.wrapErr(ErrAlgorithmPanic, "run algorithm "+strconv.Itoa(int(av.algo)), fmt.Errorf("%v", recovered))
+		}
+	}()
+	algo, _ := lookupAlgorithm(av.algo)
+	algo.run(av.image, int(av.pixelPattern), r, colorToFill, background, av.algoParams)
+	return nil
+}
+
+// validate returns the first error in the avatar configuration or its value, see WithEmptyValuePolicy.
+// Returned errors wrap ErrInvalidOption.
+func (av *Avatar) validate() error {
+	av.applyOverride()
+	if err := av.validateOptions(); err != nil {
+		return err
+	}
+	if av.overridden {
+		return nil
+	}
+	if err := av.checkValue(); err != nil {
+		return av.wrapErr(ErrInvalidOption, "check value", err)
+	}
+	return nil
+}
+
+// validateOptions returns the first error in the avatar configuration, regardless of the value.
+// Returned errors wrap ErrInvalidOption.
+func (av *Avatar) validateOptions() error {
+	if av.err != nil {
+		return av.wrapErr(ErrInvalidOption, "check options", av.err)
+	}
+	algo, ok := lookupAlgorithm(av.algo)
+	if !ok {
+		return av.wrapErr(ErrInvalidOption, "check algorithm", ErrUnknownAlgorithm)
+	}
+	if err := algo.validateParams(av.algoParams); err != nil {
+		return av.wrapErr(ErrInvalidOption, "check algorithm parameters", err)
+	}
+	if err := av.checkFormat(); err != nil {
+		return av.wrapErr(ErrInvalidOption, "check format", err)
+	}
+	if size := av.outputDimension(); size < int(av.pixelPattern) {
+		return av.wrapErr(ErrInvalidOption, "check dimension",
+			fmt.Errorf("%w: %dpx is smaller than the %dx%d pixel pattern", ErrInvalidDimension, size, av.pixelPattern, av.pixelPattern))
+	}
+	return nil
+}
+
+// WithSupersampling renders the avatar at factor times the output dimension and downsamples it with a
+// Catmull-Rom filter, smoothing the edges of masks, bevels and decorations. Factors from 2 to 8 are
+// supported, 1 turns supersampling off. Cell edges stay sharp only if WithSnapToGrid is set as well.
+func WithSupersampling(factor int) func(a *Avatar) {
+	return func(a *Avatar) {
+		if factor < 1 || factor > maxSupersampling {
+			a.err = fmt.Errorf("%w: %d", ErrInvalidSupersampling, factor)
+			return
+		}
+		a.supersampling = factor
+	}
+}
+
+// WithSnapToGrid rounds the output dimension, or the side of the pattern area inset by WithSafeArea,
+// to the nearest multiple of the pixel pattern, so all cells are equally wide. Otherwise, e.g. at 100px
+// with PIXEL_PATTERN_7, nearest neighbor scaling makes some cells one pixel wider than others.
+func WithSnapToGrid() func(a *Avatar) {
+	return func(a *Avatar) {
+		a.snapToGrid = true
+	}
+}
+
+// outputDimension returns the width and height of the output image in pixels.
+func (av *Avatar) outputDimension() int {
+	return av.snap(int(float64(av.dimension)*av.scaleFactor + 0.5))
+}
+
+// snap rounds size to the nearest multiple of the pixel pattern, if WithSnapToGrid is set.
+func (av *Avatar) snap(size int) int {
+	pattern := int(av.pixelPattern)
+	if !av.snapToGrid || pattern == 0 {
+		return size
+	}
+	return max(pattern, (size+pattern/2)/pattern*pattern)
+}
+
+// styleKey returns a string identifying every option that affects the rendered image.
+// Avatars sharing a style key render identical images for identical values and themes;
+// the theme is left out as it depends on the time of rendering.
+func (av *Avatar) styleKey() string {
+	return strconv.Itoa(int(av.pixelPattern)) + "-" +
+		strconv.Itoa(int(av.algo)) + "-" +
+		strconv.FormatBool(av.sanitizeValue) + "-" +
+		strconv.Itoa(int(av.prng)) + "-" +
+		fmt.Sprintf("%#v", av.algoParams) + av.entropySplit.key() + "-" +
+		strconv.Itoa(av.outputDimension()) + "-" +
+		strconv.FormatBool(av.snapToGrid) + "-" +
+		strconv.Itoa(av.supersampling) + "-" +
+		strconv.FormatBool(av.darkMode) +
+		paletteKey(av.palette) +
+		paletteKey([]color.Color{av.brandColor}) + "-" +
+		strconv.Itoa(int(av.colorSpace)) + av.colorBands.key() +
+		rangeKey(av.lightnessRange) + rangeKey(av.saturationRange) + "-" +
+		strconv.Itoa(int(av.twoTone)) +
+		"-fill" + paletteKey([]color.Color{av.fillColor}) + "-" +
+		strconv.FormatBool(av.gradientBackground) + "-" +
+		strconv.FormatBool(av.placeholderPhoto) + "-" +
+		strconv.FormatFloat(av.cellBevel, 'g', -1, 64) +
+		pathKey(av.maskPath) + "-" +
+		strconv.FormatFloat(av.safeArea, 'g', -1, 64) + "-" +
+		strconv.Itoa(av.maxBytes) + "-" +
+		av.format.String() + "-" +
+		strconv.FormatBool(av.svgVariables) + "-" +
+		strconv.Quote(av.svgTitle) + strconv.Quote(av.svgDesc) + "-" + av.locale +
+		av.progressRing.key() +
+		av.countBadge.key() +
+		provenanceKeyID(av.provenanceKey) +
+		jpegQualityKey(av.jpegQuality) +
+		densityKey(av.density()) +
+		av.overridesKey
 }
 
 // scaleImage scales the base image to the desired dimensions.
-func (av *Avatar) scaleImage() {
-	scaledImage := image.NewRGBA(image.Rect(0, 0, int(av.dimension), int(av.dimension)))
-	draw.NearestNeighbor.Scale(scaledImage, scaledImage.Bounds(), av.image, av.image.Bounds(), draw.Over, nil)
+// When the pattern is inset, the surrounding area is filled with the background color.
+func (av *Avatar) scaleImage(background color.Color) {
+	size := av.outputDimension()
+	scaledImage := av.canvases.get(size)
+	area := av.patternRect()
+	if area != scaledImage.Bounds() {
+		draw.Draw(scaledImage, scaledImage.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	}
+	scaleCells(scaledImage, area, av.image)
 	av.image = scaledImage
 }
 
-// saveToFile saves the generated avatar image to a file and returns the file path.
-func (av *Avatar) saveToFile() (string, error) {
-	outputPath := filepath.Join(av.path, defaultFileName)
+// fileName returns the name of the file the encoded avatar data is saved to.
+func (av *Avatar) fileName(data []byte) string {
+	name := strings.TrimSuffix(defaultFileName, filepath.Ext(defaultFileName))
+	if av.contentAddressed {
+		sum := sha256.Sum256(data)
+		name = contentAddressPrefix + hex.EncodeToString(sum[:])
+	}
+	return name + av.format.Extension()
+}
+
+// saveToFile saves the encoded avatar image to a file and returns the file path.
+func (av *Avatar) saveToFile(data []byte) (string, error) {
+	outputPath := filepath.Join(av.path, av.fileName(data))
 	outFile, err := os.Create(outputPath)
 	if err != nil {
-		return "", err
+		return "", av.wrapErr(ErrWrite, "create output file "+outputPath, err)
 	}
-	defer outFile.Close()
-	if err := png.Encode(outFile, av.image); err != nil {
+	if _, err := outFile.Write(data); err != nil {
+		outFile.Close()
+		return "", av.wrapErr(ErrWrite, "write output file "+outputPath, err)
+	}
+	if err := outFile.Close(); err != nil {
+		return "", av.wrapErr(ErrWrite, "close output file "+outputPath, err)
+	}
+	if err := av.checksum.writeSidecar(outputPath, data); err != nil {
+		return "", av.wrapErr(ErrWrite, "write checksum of "+outputPath, err)
+	}
+	if err := av.writeAlias(filepath.Base(outputPath)); err != nil {
 		return "", err
 	}
+	if av.quota != nil {
+		if err := av.quota.enforce(av.path, outputPath); err != nil {
+			return "", av.wrapErr(ErrWrite, "enforce disk quota of "+av.path, err)
+		}
+	}
 	return outputPath, nil
 }
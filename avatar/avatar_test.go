@@ -0,0 +1,84 @@
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentGenerate uses one Avatar from many goroutines, which must neither race, see
+// go test -race, nor change the avatar.
+func TestConcurrentGenerate(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	draw.Draw(logo, logo.Bounds(), image.NewUniform(color.RGBA{0x20, 0x40, 0x60, 0xff}), image.Point{}, draw.Src)
+	av := New("jane@example.com",
+		WithOutputType(OUTPUT_BUFFER),
+		WithSupersampling(2),
+		WithCellBevel(0.2),
+		WithMaxBytes(2000),
+		WithThemeProvider(func(time.Time) Theme {
+			return Theme{Name: "winter", Palette: []color.Color{color.RGBA{0, 0, 0xff, 0xff}}}
+		}),
+		WithOverrides(map[string]OverrideSpec{"jane@example.com": {Options: []CreateOption{WithDarkMode()}}}),
+	)
+	other := New("admin", WithOutputType(OUTPUT_BUFFER), WithOverrides(map[string]OverrideSpec{
+		"admin": {Image: logo},
+	}))
+	key := av.styleKey()
+	want, err := av.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOther, err := other.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				got, err := av.Generate()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if !bytes.Equal(got.Buffer.Bytes(), want.Buffer.Bytes()) {
+					t.Error("concurrent generation differs")
+				}
+				if got, err := other.Generate(); err != nil || !bytes.Equal(got.Buffer.Bytes(), wantOther.Buffer.Bytes()) {
+					t.Errorf("concurrent override differs: %v", err)
+				}
+				var svg bytes.Buffer
+				if err := av.WriteSVG(&svg); err != nil {
+					t.Error(err)
+				}
+				if _, err := av.PatternImage(); err != nil {
+					t.Error(err)
+				}
+				if _, err := av.InlineCSS(); err != nil {
+					t.Error(err)
+				}
+				if _, err := av.DescribePattern(); err != nil {
+					t.Error(err)
+				}
+				if _, err := av.Trace(); err != nil {
+					t.Error(err)
+				}
+				if _, err := av.Frames(3); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if av.styleKey() != key || av.image != nil || av.theme != nil || av.overridden {
+		t.Error("generation modified the avatar")
+	}
+}
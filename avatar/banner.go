@@ -0,0 +1,87 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"image"
+)
+
+// GenerateBanner creates a width by height image, e.g. a 1500x500 profile header, covered with
+// tiles of the avatar for value. BANNER_TILE repeats the avatar, BANNER_KALEIDOSCOPE mirrors every
+// other column and row of tiles so neighboring tiles meet seamlessly. The tiling is shifted by a
+// hash derived offset, so banners of different values do not line up. The options apply to the tiles.
+// PNG banners of 16 megapixels and more are encoded row by row without holding their pixels, unless
+// WithMaxBytes, WithBlurHash or WithLQIP need the whole image.
+func GenerateBanner(value string, width, height int, mode BannerMode, opts ...CreateOption) (*AvatarResult, error) {
+	banner := New(value, opts...)
+	if err := banner.validate(); err != nil {
+		return nil, err
+	}
+	if width <= 0 || height <= 0 {
+		return nil, banner.wrapErr(ErrInvalidOption, "check banner size", fmt.Errorf("%w: %dx%d", ErrInvalidBannerSize, width, height))
+	}
+	size := banner.outputDimension()
+	tile, err := banner.renderMember(value, size)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256([]byte(value))
+	offsetX := int(binary.BigEndian.Uint16(hash[4:6])) % size
+	offsetY := int(binary.BigEndian.Uint16(hash[6:8])) % size
+	pixel := func(x, y int) (int, int) {
+		row, ty := (y+offsetY)/size, (y+offsetY)%size
+		if mode == BANNER_KALEIDOSCOPE && row%2 == 1 {
+			ty = size - 1 - ty
+		}
+		col, tx := (x+offsetX)/size, (x+offsetX)%size
+		if mode == BANNER_KALEIDOSCOPE && col%2 == 1 {
+			tx = size - 1 - tx
+		}
+		return tx, ty
+	}
+	if banner.streamsPNG(width, height) {
+		// Rows are copied from the tile while they are encoded, the banner is never held in memory.
+		banner.scanlines = &scanlines{width: width, height: height, row: func(y int, dst []byte) {
+			for x := 0; x < width; x++ {
+				tx, ty := pixel(x, y)
+				copy(dst[4*x:4*x+4], tile.image.Pix[tile.image.PixOffset(tx, ty):])
+			}
+		}}
+		return banner.output()
+	}
+	if banner.mappedCanvas && banner.outputType != OUTPUT_RAW {
+		canvas, unmap, err := mapCanvas(banner.mappedCanvasDir, width, height)
+		if err != nil {
+			return nil, banner.wrapErr(ErrWrite, "map banner canvas", err)
+		}
+		defer unmap()
+		banner.image = canvas
+	} else {
+		banner.image = image.NewRGBA(image.Rect(0, 0, width, height))
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			banner.image.SetRGBA(x, y, tile.image.RGBAAt(pixel(x, y)))
+		}
+	}
+
+	result, err := banner.output()
+	if result != nil && banner.mappedCanvas {
+		// The mapped pixels are gone once the banner returns.
+		result.pixels = nil
+	}
+	return result, err
+}
+
+// WithMappedCanvas renders banners into a memory-mapped temporary file in dir, the default temporary
+// directory if empty, instead of the heap, so the operating system pages multi-hundred-MB canvases of
+// wallpapers to disk instead of the Go heap holding them. It only applies on Unix systems and not to
+// OUTPUT_RAW, whose result keeps the pixels. DominantColors returns nil for mapped banners.
+func WithMappedCanvas(dir string) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.mappedCanvas = true
+		a.mappedCanvasDir = dir
+	}
+}
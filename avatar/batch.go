@@ -0,0 +1,51 @@
+package avatar
+
+import (
+	"runtime"
+	"sync"
+)
+
+// BatchResult is the avatar generated for one value of GenerateBatch, or the error generating it.
+type BatchResult struct {
+	Value  string
+	Result *AvatarResult
+	Err    error
+}
+
+// GenerateBatch generates the avatars of all values, e.g. of every user of a system, with the same options,
+// which are applied once instead of once per value. The results are in the order of values and a value
+// that fails only sets the Err of its own result. Avatars are rendered on up to GOMAXPROCS goroutines,
+// WithMaxParallelism lowers the limit. With OUTPUT_FILE, combine it with WithContentAddressedNames or
+// every avatar replaces the file of the previous one.
+func GenerateBatch(values []string, opts ...CreateOption) []BatchResult {
+	base := New("", opts...)
+	results := make([]BatchResult, len(values))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for n := min(runtime.GOMAXPROCS(0), len(values)); n > 0; n-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i].Result, results[i].Err = base.batchMember(values[i]).Generate()
+			}
+		}()
+	}
+	for i, value := range values {
+		results[i].Value = value
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// batchMember returns a copy of the avatar configuration for value.
+func (av *Avatar) batchMember(value string) *Avatar {
+	member := av.Clone()
+	member.value = value
+	if av.sanitizeValue {
+		member.value = SanitizeValue(value)
+	}
+	return member
+}
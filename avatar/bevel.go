@@ -0,0 +1,53 @@
+package avatar
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// WithCellBevel shades every filled cell from light at the top to dark at the bottom,
+// giving the blocks a slight 3D bevel. Strength ranges from 0 (disabled) to 1;
+// values around 0.2 give a subtle effect. The pattern itself is not changed.
+func WithCellBevel(strength float64) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.cellBevel = math.Max(0, math.Min(1, strength))
+	}
+}
+
+// applyCellBevel shades the cells of the scaled image that are filled in the unscaled pattern.
+func (av *Avatar) applyCellBevel(pattern *image.RGBA, background color.Color) {
+	area := av.patternRect()
+	cells := pattern.Bounds().Dx()
+	size := area.Dx()
+	br, bg, bb, ba := background.RGBA()
+	for y := 0; y < size; y++ {
+		cellY := y * cells / size
+		top, bottom := cellY*size/cells, (cellY+1)*size/cells
+		t := 0.5
+		if bottom-top > 1 {
+			t = float64(y-top) / float64(bottom-top-1)
+		}
+		shade := av.cellBevel * (1 - 2*t)
+		for x := 0; x < size; x++ {
+			r, g, b, a := pattern.At(x*cells/size, cellY).RGBA()
+			if r == br && g == bg && b == bb && a == ba {
+				continue
+			}
+			px, py := area.Min.X+x, area.Min.Y+y
+			av.image.SetRGBA(px, py, shadeRGBA(av.image.RGBAAt(px, py), shade))
+		}
+	}
+}
+
+// shadeRGBA lightens a premultiplied color towards white for positive amounts
+// and darkens it towards black for negative amounts.
+func shadeRGBA(c color.RGBA, amount float64) color.RGBA {
+	shade := func(v uint8) uint8 {
+		if amount >= 0 {
+			return uint8(float64(v) + (float64(c.A)-float64(v))*amount + 0.5)
+		}
+		return uint8(float64(v)*(1+amount) + 0.5)
+	}
+	return color.RGBA{shade(c.R), shade(c.G), shade(c.B), c.A}
+}
@@ -0,0 +1,258 @@
+package avatar
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// blurHashComponents is the number of BlurHash components per axis, enough for the few large shapes
+// of an avatar at a hash length of 28 characters.
+const blurHashComponents = 4
+
+// maxThumbHashSize is the largest width and height ThumbHash encodes, larger images are scaled down.
+const maxThumbHashSize = 100
+
+// blurHashDigits are the characters of the base 83 encoding of BlurHash.
+const blurHashDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// WithBlurHash sets AvatarResult.BlurHash to the BlurHash of the rendered image, which frontends
+// decode into a blurred placeholder shown while the avatar loads. Transparent parts are composited
+// over white.
+func WithBlurHash() func(a *Avatar) {
+	return func(a *Avatar) {
+		a.blurHash = true
+	}
+}
+
+// computeBlurHash returns the BlurHash of img with the given number of components per axis, from 1 to 9.
+func computeBlurHash(img image.Image, xComponents, yComponents int) string {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	linear := make([][3]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := overWhite(img.At(bounds.Min.X+x, bounds.Min.Y+y))
+			linear[y*width+x] = [3]float64{srgbToLinear(c.R), srgbToLinear(c.G), srgbToLinear(c.B)}
+		}
+	}
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			cosX := make([]float64, width)
+			for x := range cosX {
+				cosX[x] = math.Cos(math.Pi * float64(i*x) / float64(width))
+			}
+			var f [3]float64
+			for y := 0; y < height; y++ {
+				cosY := math.Cos(math.Pi * float64(j*y) / float64(height))
+				for x := 0; x < width; x++ {
+					basis := cosX[x] * cosY
+					p := linear[y*width+x]
+					f[0] += basis * p[0]
+					f[1] += basis * p[1]
+					f[2] += basis * p[2]
+				}
+			}
+			scale := 2 / float64(width*height)
+			if i == 0 && j == 0 {
+				scale /= 2
+			}
+			factors = append(factors, [3]float64{f[0] * scale, f[1] * scale, f[2] * scale})
+		}
+	}
+
+	var b strings.Builder
+	writeBase83(&b, xComponents-1+(yComponents-1)*9, 1)
+	maximum := 1.0
+	if len(factors) > 1 {
+		var actual float64
+		for _, f := range factors[1:] {
+			actual = max(actual, math.Abs(f[0]), math.Abs(f[1]), math.Abs(f[2]))
+		}
+		quantised := int(max(0, min(82, math.Floor(actual*166-0.5))))
+		maximum = float64(quantised+1) / 166
+		writeBase83(&b, quantised, 1)
+	} else {
+		writeBase83(&b, 0, 1)
+	}
+	dc := factors[0]
+	writeBase83(&b, linearToSRGB(dc[0])<<16|linearToSRGB(dc[1])<<8|linearToSRGB(dc[2]), 4)
+	for _, f := range factors[1:] {
+		quant := func(v float64) int {
+			return int(max(0, min(18, math.Floor(signPow(v/maximum, 0.5)*9+9.5))))
+		}
+		writeBase83(&b, quant(f[0])*19*19+quant(f[1])*19+quant(f[2]), 2)
+	}
+	return b.String()
+}
+
+// writeBase83 writes value as length digits of the BlurHash base 83 encoding.
+func writeBase83(b *strings.Builder, value, length int) {
+	for i := 1; i <= length; i++ {
+		digit := value / int(math.Pow(83, float64(length-i))) % 83
+		b.WriteByte(blurHashDigits[digit])
+	}
+}
+
+// ComputeThumbHash returns the ThumbHash of img, a compact placeholder that unlike BlurHash keeps
+// the aspect ratio and transparency and is usually stored as base64. Images larger than 100x100
+// pixels are scaled down first, as ThumbHash requires.
+func ComputeThumbHash(img image.Image) []byte {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil
+	}
+	if width > maxThumbHashSize || height > maxThumbHashSize {
+		scale := float64(maxThumbHashSize) / float64(max(width, height))
+		width, height = max(1, int(float64(width)*scale+0.5)), max(1, int(float64(height)*scale+0.5))
+		scaled := image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.ApproxBiLinear.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+		img, bounds = scaled, scaled.Bounds()
+	}
+
+	pixels := make([]color.NRGBA, 0, width*height)
+	var avgR, avgG, avgB, avgA float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			pixels = append(pixels, c)
+			alpha := float64(c.A) / 0xff
+			avgR += alpha / 0xff * float64(c.R)
+			avgG += alpha / 0xff * float64(c.G)
+			avgB += alpha / 0xff * float64(c.B)
+			avgA += alpha
+		}
+	}
+	if avgA > 0 {
+		avgR, avgG, avgB = avgR/avgA, avgG/avgA, avgB/avgA
+	}
+
+	// The image is converted to a luminance and two chrominance channels, transparent pixels take
+	// the average color, so they do not darken the edges.
+	hasAlpha := avgA < float64(width*height)
+	limit := 7
+	if hasAlpha {
+		limit = 5
+	}
+	lx := max(1, int(jsRound(float64(limit*width)/float64(max(width, height)))))
+	ly := max(1, int(jsRound(float64(limit*height)/float64(max(width, height)))))
+	l, p, q, a := make([]float64, len(pixels)), make([]float64, len(pixels)), make([]float64, len(pixels)), make([]float64, len(pixels))
+	for i, c := range pixels {
+		alpha := float64(c.A) / 0xff
+		r := avgR*(1-alpha) + alpha/0xff*float64(c.R)
+		g := avgG*(1-alpha) + alpha/0xff*float64(c.G)
+		b := avgB*(1-alpha) + alpha/0xff*float64(c.B)
+		l[i], p[i], q[i], a[i] = (r+g+b)/3, (r+g)/2-b, r-g, alpha
+	}
+
+	lDC, lAC, lScale := thumbHashChannel(l, width, height, max(3, lx), max(3, ly))
+	pDC, pAC, pScale := thumbHashChannel(p, width, height, 3, 3)
+	qDC, qAC, qScale := thumbHashChannel(q, width, height, 3, 3)
+	channels := [][]float64{lAC, pAC, qAC}
+	landscape := width > height
+	header24 := int(jsRound(63*lDC)) | int(jsRound(31.5+31.5*pDC))<<6 | int(jsRound(31.5+31.5*qDC))<<12 | int(jsRound(31*lScale))<<18
+	header16 := int(jsRound(63*pScale))<<3 | int(jsRound(63*qScale))<<9
+	if landscape {
+		header16 |= ly | 1<<15
+	} else {
+		header16 |= lx
+	}
+	if hasAlpha {
+		header24 |= 1 << 23
+	}
+	hash := []byte{byte(header24), byte(header24 >> 8), byte(header24 >> 16), byte(header16), byte(header16 >> 8)}
+	if hasAlpha {
+		aDC, aAC, aScale := thumbHashChannel(a, width, height, 5, 5)
+		hash = append(hash, byte(int(jsRound(15*aDC))|int(jsRound(15*aScale))<<4))
+		channels = append(channels, aAC)
+	}
+
+	// The AC coefficients are packed as two 4 bit values per byte, low nibble first.
+	start, index := len(hash), 0
+	for _, ac := range channels {
+		for _, f := range ac {
+			if start+index>>1 == len(hash) {
+				hash = append(hash, 0)
+			}
+			hash[start+index>>1] |= byte(int(jsRound(15*f)) << (index & 1 << 2))
+			index++
+		}
+	}
+	return hash
+}
+
+// thumbHashChannel returns the DC coefficient, the AC coefficients normalized to 0 to 1, and their
+// scale, of the nx by ny lowest frequencies of a ThumbHash channel, keeping only the triangle below
+// the diagonal.
+func thumbHashChannel(channel []float64, width, height, nx, ny int) (dc float64, ac []float64, scale float64) {
+	fx := make([]float64, width)
+	for cy := 0; cy < ny; cy++ {
+		for cx := 0; cx*ny < nx*(ny-cy); cx++ {
+			for x := range fx {
+				fx[x] = math.Cos(math.Pi / float64(width) * float64(cx) * (float64(x) + 0.5))
+			}
+			var f float64
+			for y := 0; y < height; y++ {
+				fy := math.Cos(math.Pi / float64(height) * float64(cy) * (float64(y) + 0.5))
+				for x := 0; x < width; x++ {
+					f += channel[x+y*width] * fx[x] * fy
+				}
+			}
+			f /= float64(width * height)
+			if cx > 0 || cy > 0 {
+				ac = append(ac, f)
+				scale = max(scale, math.Abs(f))
+			} else {
+				dc = f
+			}
+		}
+	}
+	if scale > 0 {
+		for i := range ac {
+			ac[i] = 0.5 + 0.5/scale*ac[i]
+		}
+	}
+	return dc, ac, scale
+}
+
+// overWhite returns c composited over white.
+func overWhite(c color.Color) color.RGBA {
+	r, g, b, a := c.RGBA()
+	white := 0xffff - a
+	return color.RGBA{uint8((r + white) >> 8), uint8((g + white) >> 8), uint8((b + white) >> 8), 0xff}
+}
+
+// srgbToLinear converts an sRGB channel to linear light from 0 to 1.
+func srgbToLinear(v uint8) float64 {
+	f := float64(v) / 0xff
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB converts linear light to an sRGB channel from 0 to 255.
+func linearToSRGB(v float64) int {
+	v = max(0, min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*0xff + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*0xff + 0.5)
+}
+
+// signPow raises the magnitude of v to exp and keeps its sign.
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
+
+// jsRound rounds like JavaScript's Math.round, halves towards positive infinity, as the reference
+// ThumbHash encoder does.
+func jsRound(v float64) float64 {
+	return math.Floor(v + 0.5)
+}
@@ -0,0 +1,97 @@
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"sort"
+)
+
+// budgetPaletteSizes are the palette sizes tried in order to fit an avatar into a byte budget,
+// 0 stands for the image as rendered.
+var budgetPaletteSizes = []int{0, 256, 16, 4, 2}
+
+// WithMaxBytes limits the size of the encoded avatar to n bytes, e.g. for embedding avatars in
+// tokens, QR codes or NFC tags. The avatar is encoded with the best compression, then with fewer
+// and fewer colors, and finally at halved dimensions until it fits. Generate returns
+// ErrMaxBytesExceeded if it does not fit even at the size of the pixel pattern.
+func WithMaxBytes(n int) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.maxBytes = n
+	}
+}
+
+// encodeWithinBudget returns the largest, most detailed PNG encoding of the avatar that fits into maxBytes.
+// Smaller dimensions are rendered on a copy of the avatar, so av keeps its dimension and style key.
+func (av *Avatar) encodeWithinBudget() ([]byte, error) {
+	var buf bytes.Buffer
+	for attempt := av; ; {
+		for _, colors := range budgetPaletteSizes {
+			var img image.Image = attempt.image
+			if colors > 0 {
+				img = quantize(attempt.image, colors)
+			}
+			buf.Reset()
+			if err := encodePNG(&buf, img, attempt.density(), png.BestCompression); err != nil {
+				return nil, err
+			}
+			if buf.Len() <= av.maxBytes {
+				return buf.Bytes(), nil
+			}
+		}
+		if attempt.dimension/2 < uint(av.pixelPattern) {
+			return nil, ErrMaxBytesExceeded
+		}
+		smaller := *attempt
+		smaller.dimension /= 2
+		attempt = &smaller
+		if err := attempt.render(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// quantize maps img to a palette of at most n of its most frequent colors.
+func quantize(img *image.RGBA, n int) *image.Paletted {
+	counts := make(map[color.RGBA]int)
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			counts[img.RGBAAt(x, y)]++
+		}
+	}
+	colors := byCount(counts)
+	if len(colors) > n {
+		colors = colors[:n]
+	}
+	palette := make(color.Palette, len(colors))
+	for i, c := range colors {
+		palette[i] = c
+	}
+
+	paletted := image.NewPaletted(bounds, palette)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			paletted.SetColorIndex(x, y, uint8(palette.Index(img.RGBAAt(x, y))))
+		}
+	}
+	return paletted
+}
+
+// byCount returns the colors of counts from the most to the least frequent, ties in RGBA order.
+func byCount(counts map[color.RGBA]int) []color.RGBA {
+	colors := make([]color.RGBA, 0, len(counts))
+	for c := range counts {
+		colors = append(colors, c)
+	}
+	sort.Slice(colors, func(i, j int) bool {
+		if counts[colors[i]] != counts[colors[j]] {
+			return counts[colors[i]] > counts[colors[j]]
+		}
+		a, b := colors[i], colors[j]
+		return uint32(a.R)<<24|uint32(a.G)<<16|uint32(a.B)<<8|uint32(a.A) <
+			uint32(b.R)<<24|uint32(b.G)<<16|uint32(b.B)<<8|uint32(b.A)
+	})
+	return colors
+}
@@ -0,0 +1,36 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestMaxBytesKeepsAvatar(t *testing.T) {
+	// Noise does not compress, so it only fits at a smaller dimension.
+	av := New("jane@example.com", WithDimension(512), WithPixelPattern(PIXEL_PATTERN_12), WithAlgorithm(ALGORITHM_3),
+		WithCellBevel(0.3), WithMaxBytes(200), WithOutputType(OUTPUT_BUFFER))
+	key := av.styleKey()
+	first, err := av.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := first.Buffer.Len(); n > 200 {
+		t.Errorf("got %d bytes, want at most 200", n)
+	}
+	if av.dimension != 512 || av.styleKey() != key {
+		t.Errorf("avatar changed: dimension %d", av.dimension)
+	}
+	second, err := av.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Buffer.Bytes(), second.Buffer.Bytes()) {
+		t.Error("second generation differs")
+	}
+
+	_, err = New("jane@example.com", WithMaxBytes(10), WithOutputType(OUTPUT_BUFFER)).Generate()
+	if !errors.Is(err, ErrMaxBytesExceeded) {
+		t.Errorf("got %v, want ErrMaxBytesExceeded", err)
+	}
+}
@@ -0,0 +1,45 @@
+package avatar
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+)
+
+// Bundle is an avatar encoded both as SVG and as PNG, for pages that serve the SVG where browsers
+// support it and the PNG everywhere else.
+type Bundle struct {
+	SVG []byte
+	PNG []byte
+	// Dimension is the width and height the avatar is displayed at, without the scale factor.
+	Dimension int
+}
+
+// GenerateBundle renders the avatar for value once and encodes it as SVG and as PNG. The format
+// option is ignored, all other options apply to both encodings.
+func GenerateBundle(value string, opts ...CreateOption) (*Bundle, error) {
+	av := New(value, opts...)
+	av.format = FORMAT_PNG
+	if err := av.validate(); err != nil {
+		return nil, err
+	}
+	if err := av.render(); err != nil {
+		return nil, err
+	}
+	var svg bytes.Buffer
+	if err := av.writeSVG(&svg); err != nil {
+		return nil, av.wrapErr(ErrEncode, "encode svg", err)
+	}
+	png, err := av.encodeBytes()
+	if err != nil {
+		return nil, err
+	}
+	return &Bundle{SVG: svg.Bytes(), PNG: png, Dimension: int(av.dimension)}, nil
+}
+
+// Picture returns a <picture> element showing the SVG served at svgURL, or the PNG served at pngURL
+// in browsers without SVG support, with the given alternative text.
+func (b *Bundle) Picture(svgURL, pngURL, alt string) string {
+	return fmt.Sprintf(`<picture><source srcset="%s" type="image/svg+xml"><img src="%s" width="%d" height="%d" alt="%s"></picture>`,
+		html.EscapeString(svgURL), html.EscapeString(pngURL), b.Dimension, b.Dimension, html.EscapeString(alt))
+}
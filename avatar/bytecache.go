@@ -0,0 +1,37 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// ByteCache stores encoded avatars outside of the process, e.g. in Redis, so a fleet of servers
+// shares generated avatars instead of every node rendering and encoding the same hot values.
+// Implementations must be safe for concurrent use.
+type ByteCache interface {
+	// Get returns the data stored for key and whether it was found.
+	Get(key string) ([]byte, bool, error)
+	// Set stores data for key, expiring after ttl unless ttl is zero.
+	Set(key string, data []byte, ttl time.Duration) error
+}
+
+// byteCachePrefix prefixes the keys of avatars in a ByteCache.
+const byteCachePrefix = "godenticon:"
+
+// WithByteCache makes Generators look up avatars missing from their in-memory cache in cache, and
+// store the avatars they render in it for ttl, zero storing them without expiry. Keys are derived from
+// the hash of the value and the options, so Generators with identical options share their avatars.
+// Errors of the cache count as misses, an unavailable cache only costs rendering the avatars again.
+func WithByteCache(cache ByteCache, ttl time.Duration) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.byteCache = cache
+		a.byteCacheTTL = ttl
+	}
+}
+
+// byteCacheKey returns the key of the avatar with the given Generator cache key in a ByteCache.
+func byteCacheKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return byteCachePrefix + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,188 @@
+package avatar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bugcacher/godenticon/internal/store"
+)
+
+// CachingFS is a file system of avatar files named like the files of "godenticon warm",
+// e.g. "jane@example.com.png", with the extension of the format set by WithFormat, in a directory. Avatar files that do not exist yet are generated
+// and saved on first access, so static file servers gain avatars without handler code:
+//
+//	http.Handle("/avatars/", http.StripPrefix("/avatars/", http.FileServer(http.FS(cfs))))
+//
+// CachingFS implements fs.FS and is safe for concurrent use.
+type CachingFS struct {
+	dir  string
+	root fs.FS
+	gen  *Generator
+	// extension is the file extension of the avatar files, see Generator.Extension.
+	extension string
+	ttl       time.Duration
+	quota     *diskQuota
+	// checksum is the checksum files are verified against, see WithChecksumSidecar.
+	checksum Checksum
+	// OnError, when set, is called with errors encountered by the janitor, see StartJanitor.
+	OnError func(err error)
+}
+
+// NewCachingFS creates a CachingFS storing avatars generated with the given options in dir.
+// The files are the only cache, avatars are not kept in memory once saved.
+func NewCachingFS(dir string, opts ...CreateOption) *CachingFS {
+	config := New("", opts...)
+	// The disabled memory cache still makes concurrent opens of a missing file render it once.
+	gen := newGenerator(newMemoryCache(0, 0), newCanvasPool(), opts)
+	return &CachingFS{dir: dir, root: os.DirFS(dir), gen: gen, extension: gen.Extension(), ttl: config.ttl, quota: config.quota,
+		checksum: config.checksum}
+}
+
+// WithTTL makes the avatar files of a CachingFS expire d after they were saved. Open regenerates
+// expired files and CleanExpired or the janitor started by StartJanitor removes them, so avatars
+// generated on demand do not fill the disk of long running servers.
+func WithTTL(d time.Duration) func(a *Avatar) {
+	return func(a *Avatar) {
+		if d <= 0 {
+			a.err = fmt.Errorf("%w: %v", ErrInvalidTTL, d)
+			return
+		}
+		a.ttl = d
+	}
+}
+
+// Open opens the named file, generating it first if it is a missing or expired avatar file in the
+// top directory, or one that does not match its checksum file with WithChecksumSidecar.
+func (c *CachingFS) Open(name string) (fs.File, error) {
+	value, ok := store.Value(name, c.extension)
+	ok = ok && filepath.Base(name) == name
+	f, err := c.root.Open(name)
+	if err == nil {
+		if !ok || !c.expired(f) && c.checksum.verify(filepath.Join(c.dir, name)) {
+			if ok && c.quota != nil {
+				c.quota.touch(filepath.Join(c.dir, name))
+			}
+			return f, nil
+		}
+		f.Close()
+	} else if !errors.Is(err, fs.ErrNotExist) || !ok {
+		return nil, err
+	}
+	if err := c.generate(value, name); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return c.root.Open(name)
+}
+
+// generate saves the avatar for value as the named file, replacing it atomically.
+func (c *CachingFS) generate(value, name string) error {
+	data, err := c.gen.Generate(value)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, ".avatar-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	path := filepath.Join(c.dir, name)
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := c.checksum.writeSidecar(path, data); err != nil {
+		return err
+	}
+	if c.quota != nil {
+		return c.quota.enforce(c.dir, path)
+	}
+	return nil
+}
+
+// expired reports whether the avatar file f was saved longer than the TTL ago.
+func (c *CachingFS) expired(f fs.File) bool {
+	if c.ttl == 0 {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && time.Since(info.ModTime()) > c.ttl
+}
+
+// CleanExpired removes the avatar files saved longer than the TTL set by WithTTL ago and returns
+// how many it removed. Without a TTL no files expire.
+func (c *CachingFS) CleanExpired() (int, error) {
+	if c.ttl == 0 {
+		return 0, nil
+	}
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	removed := 0
+	for _, entry := range entries {
+		if _, ok := store.Value(entry.Name(), c.extension); !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) <= c.ttl {
+			continue
+		}
+		path := filepath.Join(c.dir, entry.Name())
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, err
+		}
+		if c.quota != nil {
+			c.quota.forget(path)
+		}
+		if err := removeSidecars(path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// StartJanitor calls CleanExpired every interval until ctx is done, reporting errors to OnError.
+// A zero interval cleans once per TTL.
+func (c *CachingFS) StartJanitor(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = c.ttl
+	}
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := c.CleanExpired(); err != nil && c.OnError != nil {
+					c.OnError(err)
+				}
+			}
+		}
+	}()
+}
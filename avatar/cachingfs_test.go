@@ -0,0 +1,58 @@
+package avatar
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCachingFS(t *testing.T) {
+	dir := t.TempDir()
+	cfs := NewCachingFS(dir)
+	f, err := cfs.Open("jane@example.com.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	served, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(filepath.Join(dir, "jane@example.com.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(served) != string(saved) {
+		t.Error("served avatar differs from the saved file")
+	}
+	if n := cfs.gen.cache.order.Len(); n != 0 {
+		t.Errorf("got %d avatars cached in memory, want 0", n)
+	}
+	if _, err := cfs.Open("../outside.png"); err == nil {
+		t.Error("opened a file outside of the directory")
+	}
+}
+
+func TestCachingFSFormat(t *testing.T) {
+	dir := t.TempDir()
+	cfs := NewCachingFS(dir, WithFormat(FORMAT_SVG))
+	f, err := cfs.Open("jane@example.com.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	served, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(served), "<svg") {
+		t.Errorf("got %.20q, want an SVG", served)
+	}
+	if _, err := cfs.Open("jane@example.com.png"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("PNG name of an SVG avatar: got %v, want fs.ErrNotExist", err)
+	}
+}
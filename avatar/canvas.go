@@ -0,0 +1,61 @@
+package avatar
+
+import (
+	"image"
+	"runtime"
+	"sync"
+)
+
+// canvasPool keeps output canvases of preallocated sizes for reuse, so servers rendering a few fixed
+// sizes do not allocate a large image per avatar. A nil pool allocates every canvas.
+type canvasPool struct {
+	mu   sync.Mutex
+	free map[int][]*image.RGBA
+}
+
+func newCanvasPool() *canvasPool {
+	return &canvasPool{free: make(map[int][]*image.RGBA)}
+}
+
+// maxPooledCanvases returns the number of canvases kept per size, enough for one render per CPU.
+func maxPooledCanvases() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// preallocate makes size a pooled size and allocates a canvas of that size.
+func (p *canvasPool) preallocate(size int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.free[size]) == 0 {
+		p.free[size] = append(p.free[size], image.NewRGBA(image.Rect(0, 0, size, size)))
+	}
+}
+
+// get returns a size by size canvas with undefined content.
+func (p *canvasPool) get(size int) *image.RGBA {
+	if p != nil {
+		p.mu.Lock()
+		free := p.free[size]
+		if n := len(free); n > 0 {
+			canvas := free[n-1]
+			p.free[size] = free[:n-1]
+			p.mu.Unlock()
+			return canvas
+		}
+		p.mu.Unlock()
+	}
+	return image.NewRGBA(image.Rect(0, 0, size, size))
+}
+
+// put returns a canvas that is no longer used to the pool if its size was preallocated.
+func (p *canvasPool) put(canvas *image.RGBA) {
+	if p == nil || canvas == nil || canvas.Rect.Min != (image.Point{}) || canvas.Rect.Dx() != canvas.Rect.Dy() {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	free, ok := p.free[canvas.Rect.Dx()]
+	if ok && len(free) < maxPooledCanvases() {
+		p.free[canvas.Rect.Dx()] = append(free, canvas)
+	}
+}
@@ -0,0 +1,31 @@
+package avatar
+
+import (
+	"fmt"
+	"testing"
+)
+
+// BenchmarkCanvasPool renders avatars onto preallocated canvases, as Generators do, and onto a new
+// canvas per avatar.
+func BenchmarkCanvasPool(b *testing.B) {
+	for _, size := range []uint{64, 256, 1024} {
+		for _, pooled := range []bool{false, true} {
+			b.Run(fmt.Sprintf("%dpx/pooled=%t", size, pooled), func(b *testing.B) {
+				var pool *canvasPool
+				if pooled {
+					pool = newCanvasPool()
+					pool.preallocate(int(size))
+				}
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					av := New("abhinavsingh", WithDimension(size), WithOutputType(OUTPUT_RAW))
+					av.canvases = pool
+					if err := av.render(); err != nil {
+						b.Fatal(err)
+					}
+					pool.put(av.image)
+				}
+			})
+		}
+	}
+}
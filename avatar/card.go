@@ -0,0 +1,62 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// shortFingerprintBytes is the number of hash bytes shown by ShortFingerprint.
+const shortFingerprintBytes = 8
+
+// ShortFingerprint returns the first bytes of the hash of value as upper case hex in groups of four,
+// e.g. "9F86 D081 884C 7D65", short enough to be compared by eye or read aloud when pairing devices.
+func ShortFingerprint(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	digits := strings.ToUpper(hex.EncodeToString(hash[:shortFingerprintBytes]))
+	groups := make([]string, 0, len(digits)/4)
+	for i := 0; i < len(digits); i += 4 {
+		groups = append(groups, digits[i:i+4])
+	}
+	return strings.Join(groups, " ")
+}
+
+// GenerateIdentityCard creates an image showing the avatar for value beside the value and its
+// ShortFingerprint, for device pairing and key verification screens. The card is as high as the
+// avatar and three times as wide. Values that do not fit are shortened with a trailing "...", and
+// characters outside of ASCII are drawn as in GenerateSocialCard. The options apply to the avatar on the card.
+func GenerateIdentityCard(value string, opts ...CreateOption) (*AvatarResult, error) {
+	card := New(value, opts...)
+	if err := card.validate(); err != nil {
+		return nil, err
+	}
+	size := card.outputDimension()
+	icon, err := card.renderMember(value, size)
+	if err != nil {
+		return nil, err
+	}
+
+	background, foreground := getBackgroundColor(card.darkMode), color.Color(color.Black)
+	if card.darkMode {
+		foreground = color.White
+	}
+	card.image = image.NewRGBA(image.Rect(0, 0, 3*size, size))
+	draw.Draw(card.image, card.image.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(card.image, image.Rect(0, 0, size, size), icon.image, image.Point{}, draw.Src)
+
+	padding := size / 8
+	x, width := size+padding, 2*size-2*padding
+	fingerprint := ShortFingerprint(value)
+	scale := fitTextScale(fingerprint, width, (size-3*padding)/2)
+	label := truncateText(value, width, scale)
+	gap := textHeight(scale)
+	y := (size - 3*textHeight(scale)) / 2
+	drawText(card.image, label, x, y, scale, foreground)
+	drawText(card.image, fingerprint, x, y+textHeight(scale)+gap, scale, foreground)
+
+	return card.output()
+}
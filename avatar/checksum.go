@@ -0,0 +1,99 @@
+package avatar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// checksumExtensions are the file extensions of the sidecar files of each checksum.
+var checksumExtensions = map[Checksum]string{
+	CHECKSUM_SHA256: ".sha256",
+	CHECKSUM_SHA512: ".sha512",
+}
+
+// WithChecksumSidecar writes a checksum file beside every saved avatar, e.g. "avatar.png.sha256" with
+// CHECKSUM_SHA256, for pipelines that verify the integrity of their artifacts. The files have the format
+// of sha256sum and sha512sum, so "sha256sum -c avatar.png.sha256" checks an avatar. A CachingFS verifies
+// avatar files against their checksum when opening them and regenerates files that do not match.
+func WithChecksumSidecar(checksum Checksum) func(a *Avatar) {
+	return func(a *Avatar) {
+		if _, ok := checksumExtensions[checksum]; !ok && checksum != CHECKSUM_NONE {
+			a.err = fmt.Errorf("%w: %d", ErrUnknownChecksum, checksum)
+			return
+		}
+		a.checksum = checksum
+	}
+}
+
+// sum returns the hex encoded checksum of data.
+func (c Checksum) sum(data []byte) string {
+	if c == CHECKSUM_SHA512 {
+		sum := sha512.Sum512(data)
+		return hex.EncodeToString(sum[:])
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// sidecarLine returns the content of the checksum file of the avatar file at path holding data.
+func (c Checksum) sidecarLine(path string, data []byte) []byte {
+	return []byte(c.sum(data) + "  " + filepath.Base(path) + "\n")
+}
+
+// writeSidecar writes the checksum file of the avatar file at path holding data, replacing it atomically.
+func (c Checksum) writeSidecar(path string, data []byte) error {
+	if c == CHECKSUM_NONE {
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".checksum-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(c.sidecarLine(path, data))
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path+checksumExtensions[c])
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// verify reports whether the avatar file at path matches its checksum file.
+func (c Checksum) verify(path string) bool {
+	if c == CHECKSUM_NONE {
+		return true
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	line, err := os.ReadFile(path + checksumExtensions[c])
+	return err == nil && bytes.Equal(line, c.sidecarLine(path, data))
+}
+
+// removeSidecars removes the checksum and provenance files of the avatar file at path.
+func removeSidecars(path string) error {
+	for _, ext := range checksumExtensions {
+		if err := os.Remove(path + ext); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Remove(path + provenanceExtension); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
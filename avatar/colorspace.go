@@ -0,0 +1,139 @@
+package avatar
+
+import (
+	"image/color"
+	"math"
+)
+
+// toHSL converts a color to hue (0-360), saturation (0-1) and lightness (0-1).
+func toHSL(c color.Color) (h, s, l float64) {
+	r16, g16, b16, _ := color.NRGBAModel.Convert(c).RGBA()
+	r, g, b := float64(r16)/0xffff, float64(g16)/0xffff, float64(b16)/0xffff
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l = (max + min) / 2
+	if max == min {
+		return 0, 0, l
+	}
+	d := max - min
+	if l > 0.5 {
+		s = d / (2 - max - min)
+	} else {
+		s = d / (max + min)
+	}
+	switch max {
+	case r:
+		h = (g - b) / d
+		if g < b {
+			h += 6
+		}
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h * 60, s, l
+}
+
+// fromHSL converts hue (0-360), saturation (0-1) and lightness (0-1) to an opaque color.
+func fromHSL(h, s, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	hp := math.Mod(h, 360) / 60
+	if hp < 0 {
+		hp += 6
+	}
+	x := c * (1 - math.Abs(math.Mod(hp, 2)-1))
+	var r, g, b float64
+	switch {
+	case hp < 1:
+		r, g, b = c, x, 0
+	case hp < 2:
+		r, g, b = x, c, 0
+	case hp < 3:
+		r, g, b = 0, c, x
+	case hp < 4:
+		r, g, b = 0, x, c
+	case hp < 5:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	m := l - c/2
+	return color.RGBA{unitToByte(r + m), unitToByte(g + m), unitToByte(b + m), 0xff}
+}
+
+// unitToByte converts a value in the range 0-1 to 0-255, clamping out of range values.
+func unitToByte(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * 0xff))
+}
+
+// hashFraction maps four bytes of a hash to a fraction in the range 0-1.
+func hashFraction(b []byte) float64 {
+	return float64(uint32(b[0])<<24|uint32(b[1])<<16|uint32(b[2])<<8|uint32(b[3])) / math.MaxUint32
+}
+
+// contrastLuminance is the luminance above which black contrasts better than white.
+const contrastLuminance = 0.179
+
+// luminance returns the relative luminance (0-1) of a color as defined by WCAG.
+func luminance(c color.Color) float64 {
+	r, g, b, _ := color.NRGBAModel.Convert(c).RGBA()
+	linear := func(v uint32) float64 {
+		f := float64(v) / 0xffff
+		if f <= 0.03928 {
+			return f / 12.92
+		}
+		return math.Pow((f+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
+
+// contrastRatio returns the WCAG contrast ratio (1-21) of two colors.
+func contrastRatio(a, b color.Color) float64 {
+	la, lb := luminance(a), luminance(b)
+	return (max(la, lb) + 0.05) / (min(la, lb) + 0.05)
+}
+
+// oklchToRGB converts an OKLCH color, with the hue in degrees, to linear sRGB.
+func oklchToRGB(l, c, h float64) (r, g, b float64) {
+	a, bb := c*math.Cos(h*math.Pi/180), c*math.Sin(h*math.Pi/180)
+	lc := math.Pow(l+0.3963377774*a+0.2158037573*bb, 3)
+	mc := math.Pow(l-0.1055613458*a-0.0638541728*bb, 3)
+	sc := math.Pow(l-0.0894841775*a-1.2914855480*bb, 3)
+	return 4.0767416621*lc - 3.3077115913*mc + 0.2309699292*sc,
+		-1.2684380046*lc + 2.6097574011*mc - 0.3413193965*sc,
+		-0.0041960863*lc - 0.7034186147*mc + 1.7076147010*sc
+}
+
+// cielchToRGB converts a CIE LCh(ab) color under the D65 white point, with the hue in degrees,
+// to linear sRGB.
+func cielchToRGB(l, c, h float64) (r, g, b float64) {
+	a, bb := c*math.Cos(h*math.Pi/180), c*math.Sin(h*math.Pi/180)
+	finv := func(t float64) float64 {
+		if t > 6.0/29 {
+			return t * t * t
+		}
+		return 3 * (6.0 / 29) * (6.0 / 29) * (t - 4.0/29)
+	}
+	fy := (l + 16) / 116
+	x, y, z := 0.95047*finv(fy+a/500), finv(fy), 1.08883*finv(fy-bb/200)
+	return 3.2404542*x - 1.5371385*y - 0.4985314*z,
+		-0.9692660*x + 1.8760108*y + 0.0415560*z,
+		0.0556434*x - 0.2040259*y + 1.0572252*z
+}
+
+// inGamut reports whether linear sRGB components are displayable.
+func inGamut(r, g, b float64) bool {
+	const eps = 1e-6
+	return r >= -eps && r <= 1+eps && g >= -eps && g <= 1+eps && b >= -eps && b <= 1+eps
+}
+
+// linearToRGBA gamma encodes linear sRGB components to an opaque color.
+func linearToRGBA(r, g, b float64) color.RGBA {
+	encode := func(v float64) uint8 {
+		if v <= 0.0031308 {
+			return unitToByte(12.92 * v)
+		}
+		return unitToByte(1.055*math.Pow(v, 1/2.4) - 0.055)
+	}
+	return color.RGBA{encode(r), encode(g), encode(b), 0xff}
+}
@@ -5,14 +5,125 @@ type Algorithm int
 const (
 	ALGORITHM_1 Algorithm = iota
 	ALGORITHM_2
+	// ALGORITHM_3 fills every cell independently, so patterns are not mirror symmetric.
+	ALGORITHM_3
 )
 
 type PixelPattern uint
 
 const (
-	PIXEL_PATTERN_5 PixelPattern = 5
-	PIXEL_PATTERN_7 PixelPattern = 7
-	PIXEL_PATTERN_9 PixelPattern = 9
+	PIXEL_PATTERN_5  PixelPattern = 5
+	PIXEL_PATTERN_7  PixelPattern = 7
+	PIXEL_PATTERN_9  PixelPattern = 9
+	PIXEL_PATTERN_12 PixelPattern = 12
+)
+
+// pixelPatterns are the supported pixel patterns, smallest first.
+var pixelPatterns = []PixelPattern{PIXEL_PATTERN_5, PIXEL_PATTERN_7, PIXEL_PATTERN_9, PIXEL_PATTERN_12}
+
+type PRNG int
+
+const (
+	PRNG_MATH_RAND PRNG = iota
+	PRNG_XOSHIRO
+	PRNG_PCG
+	PRNG_CHACHA8
+)
+
+type Corner int
+
+const (
+	CORNER_TOP_RIGHT Corner = iota
+	CORNER_BOTTOM_RIGHT
+	CORNER_BOTTOM_LEFT
+	CORNER_TOP_LEFT
+)
+
+type Axis int
+
+const (
+	AXIS_VERTICAL Axis = iota
+	AXIS_HORIZONTAL
+)
+
+type ColorName int
+
+const (
+	COLOR_GRAY ColorName = iota
+	COLOR_RED
+	COLOR_ORANGE
+	COLOR_YELLOW
+	COLOR_GREEN
+	COLOR_CYAN
+	COLOR_BLUE
+	COLOR_PURPLE
+	COLOR_PINK
+)
+
+type Density int
+
+const (
+	DENSITY_SPARSE Density = iota
+	DENSITY_BALANCED
+	DENSITY_DENSE
+)
+
+type Emphasis int
+
+const (
+	EMPHASIS_EVEN Emphasis = iota
+	EMPHASIS_CENTER
+	EMPHASIS_EDGES
+)
+
+type ColorSpace int
+
+const (
+	COLOR_SPACE_RGB ColorSpace = iota
+	COLOR_SPACE_OKLCH
+	COLOR_SPACE_CIELCH
+)
+
+type TwoTone int
+
+const (
+	TWO_TONE_NONE TwoTone = iota
+	TWO_TONE_ALTERNATING
+	TWO_TONE_HALVES
+)
+
+type BannerMode int
+
+const (
+	BANNER_TILE BannerMode = iota
+	BANNER_KALEIDOSCOPE
+)
+
+type Alias int
+
+const (
+	ALIAS_NONE Alias = iota
+	ALIAS_SYMLINK
+	ALIAS_MANIFEST
+)
+
+type ManifestFormat int
+
+const (
+	MANIFEST_JSON ManifestFormat = iota
+	MANIFEST_CSV
+)
+
+type Format int
+
+const (
+	FORMAT_PNG Format = iota
+	FORMAT_JPEG
+	FORMAT_GIF
+	FORMAT_WEBP
+	FORMAT_AVIF
+	FORMAT_SVG
+	FORMAT_HTML
 )
 
 type Output int
@@ -20,8 +131,45 @@ type Output int
 const (
 	OUTPUT_FILE Output = iota
 	OUTPUT_BUFFER
+	OUTPUT_RAW
+)
+
+type DeadlinePolicy int
+
+const (
+	DEADLINE_FAIL DeadlinePolicy = iota
+	DEADLINE_DOWNGRADE
+)
+
+type EmptyValuePolicy int
+
+const (
+	EMPTY_VALUE_ERROR EmptyValuePolicy = iota
+	EMPTY_VALUE_HASH
+	EMPTY_VALUE_PLACEHOLDER
+)
+
+type Checksum int
+
+const (
+	CHECKSUM_NONE Checksum = iota
+	CHECKSUM_SHA256
+	CHECKSUM_SHA512
+)
+
+// maxSupersampling is the largest factor accepted by WithSupersampling.
+const maxSupersampling = 8
+
+const (
+	defaultFileName      = "avatar.png"
+	contentAddressPrefix = "sha256-"
+	aliasManifestName    = "aliases.json"
 )
 
+// Saturation and lightness bands used for shades of a brand color.
 const (
-	defaultFileName = "avatar.png"
+	brandMinSaturation   = 0.35
+	brandSaturationRange = 0.55
+	brandMinLightness    = 0.30
+	brandLightnessRange  = 0.40
 )
@@ -0,0 +1,28 @@
+package avatar
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bugcacher/godenticon/corpus"
+)
+
+func TestCorpus(t *testing.T) {
+	f, err := os.Open("../testdata/corpus.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := corpus.Read(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, algo := range registeredAlgorithms() {
+		for _, value := range values {
+			_, err := New(value, WithAlgorithm(algo), WithEmptyValuePolicy(EMPTY_VALUE_HASH), WithOutputType(OUTPUT_BUFFER)).Generate()
+			if err != nil {
+				t.Errorf("algorithm %d, %.32q: %v", algo, value, err)
+			}
+		}
+	}
+}
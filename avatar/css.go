@@ -0,0 +1,75 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// InlineCSS returns the avatar as the declarations of an inline style attribute, for emails and other
+// places where images are blocked but inline CSS renders:
+//
+//	`<div style="` + css + `"></div>`
+//
+// The element is sized to the output dimension and every horizontal run of cells of one color is a
+// positioned background layer of a solid linear gradient over the background color. Like SVG output, it shows the pattern and
+// its colors without raster effects such as masks, bevels and decorations.
+func (av *Avatar) InlineCSS() (string, error) {
+	av, err := av.prepare()
+	if err != nil {
+		return "", err
+	}
+	pattern, err := av.patternImage()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(av.value))
+	if av.placeholderPhoto {
+		return av.photoCSS(hash), nil
+	}
+	_, background := av.patternColors(hash)
+	if av.twoTone != TWO_TONE_NONE {
+		av.applyTwoTone(hash, background)
+	}
+	size, area := av.outputDimension(), av.patternRect()
+	cells := int(av.pixelPattern)
+	edge := func(i int) int { return i * area.Dx() / cells }
+
+	var layers []string
+	for y := 0; y < cells; y++ {
+		for x := 0; x < cells; {
+			c := pattern.At(x, y)
+			run := 1
+			for x+run < cells && sameColor(pattern.At(x+run, y), c) {
+				run++
+			}
+			if !sameColor(c, background) {
+				css := cssColor(c)
+				layers = append(layers, fmt.Sprintf("linear-gradient(%s,%s) %dpx %dpx/%dpx %dpx no-repeat",
+					css, css, area.Min.X+edge(x), area.Min.Y+edge(y), edge(x+run)-edge(x), edge(y+1)-edge(y)))
+			}
+			x += run
+		}
+	}
+
+	// The background color can only be given in the last layer.
+	if bg := color.NRGBAModel.Convert(background).(color.NRGBA); bg.A > 0 {
+		layers = append(layers, cssColor(background))
+	}
+	css := fmt.Sprintf("display:inline-block;width:%dpx;height:%dpx;", size, size)
+	if len(layers) > 0 {
+		css += "background:" + strings.Join(layers, ",") + ";"
+	}
+	return css, nil
+}
+
+// cssColor returns c in the CSS hex notation, or as rgba() if it is translucent.
+func cssColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if n.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+	}
+	return fmt.Sprintf("rgba(%d,%d,%d,%s)", n.R, n.G, n.B, strconv.FormatFloat(float64(n.A)/0xff, 'f', 3, 64))
+}
@@ -0,0 +1,51 @@
+package avatar
+
+import (
+	"context"
+	"time"
+)
+
+// downgradeDimension is the largest dimension, in pixels, of avatars downgraded by DEADLINE_DOWNGRADE.
+const downgradeDimension = 64
+
+// downgradeSuffix distinguishes the cache keys of downgraded avatars.
+const downgradeSuffix = "\x00downgraded"
+
+// WithDeadlinePolicy sets what GenerateContext does when less than margin is left until the deadline
+// of its context. DEADLINE_FAIL, the default, renders the avatar as usual and only returns the
+// context's error once it is done. DEADLINE_DOWNGRADE instead renders a cheaper avatar, at most 64px
+// large and without supersampling and bevels, even past the deadline, so servers answer slow requests
+// with a smaller avatar instead of an error. Generators cache the downgraded avatars separately and
+// serve them to later requests close to their deadline.
+func WithDeadlinePolicy(policy DeadlinePolicy, margin time.Duration) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.deadlinePolicy = policy
+		a.deadlineMargin = margin
+	}
+}
+
+// nearDeadline reports whether ctx is done or less than margin is left until its deadline.
+func nearDeadline(ctx context.Context, margin time.Duration) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	deadline, ok := ctx.Deadline()
+	return ok && time.Until(deadline) < margin
+}
+
+// withDowngrade downgrades the avatar, it must be applied after the other options.
+func withDowngrade() func(a *Avatar) {
+	return func(a *Avatar) {
+		a.downgrade()
+	}
+}
+
+// downgrade makes the avatar cheaper to render: at most downgradeDimension large, without
+// supersampling and without bevels.
+func (av *Avatar) downgrade() {
+	if av.outputDimension() > downgradeDimension {
+		av.dimension, av.scaleFactor = downgradeDimension, 1
+	}
+	av.supersampling = 0
+	av.cellBevel = 0
+}
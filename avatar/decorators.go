@@ -0,0 +1,192 @@
+package avatar
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+)
+
+// decoratorSubsamples is the number of samples per pixel axis used to anti-alias decorators.
+const decoratorSubsamples = 4
+
+// ringWidth is the width of the progress ring relative to the avatar dimension.
+const ringWidth = 0.07
+
+// progressRing is a partial ring drawn around the avatar.
+type progressRing struct {
+	fraction float64
+	color    color.Color
+}
+
+// WithProgressRing draws a ring along the edge of the avatar that is filled clockwise from the top
+// by fraction (0-1), e.g. to show profile completeness or storage quota.
+func WithProgressRing(fraction float64, c color.Color) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.progressRing = &progressRing{fraction: math.Max(0, math.Min(1, fraction)), color: c}
+	}
+}
+
+// decorate draws the configured decorators on top of the rendered image.
+func (av *Avatar) decorate() {
+	if av.progressRing != nil {
+		av.progressRing.draw(av.image)
+	}
+	if av.countBadge != nil {
+		av.countBadge.draw(av.image)
+	}
+}
+
+// draw renders the ring onto img with anti-aliased edges.
+func (r *progressRing) draw(img *image.RGBA) {
+	bounds := img.Bounds()
+	size := float64(bounds.Dx())
+	center := size / 2
+	outer := center
+	inner := center - size*ringWidth
+	end := r.fraction * 2 * math.Pi
+
+	coverage := func(x, y float64) bool {
+		dx, dy := x-center, y-center
+		distance := math.Hypot(dx, dy)
+		if distance < inner || distance > outer {
+			return false
+		}
+		angle := math.Atan2(dx, -dy)
+		if angle < 0 {
+			angle += 2 * math.Pi
+		}
+		return angle <= end
+	}
+	drawCoverage(img, r.color, coverage)
+}
+
+// drawCoverage blends c into every pixel of img weighted by the fraction of subsamples covered by the shape.
+func drawCoverage(img *image.RGBA, c color.Color, covered func(x, y float64) bool) {
+	bounds := img.Bounds()
+	cr, cg, cb, ca := c.RGBA()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			hits := 0
+			for sy := 0; sy < decoratorSubsamples; sy++ {
+				for sx := 0; sx < decoratorSubsamples; sx++ {
+					px := float64(x-bounds.Min.X) + (float64(sx)+0.5)/decoratorSubsamples
+					py := float64(y-bounds.Min.Y) + (float64(sy)+0.5)/decoratorSubsamples
+					if covered(px, py) {
+						hits++
+					}
+				}
+			}
+			if hits == 0 {
+				continue
+			}
+			coverage := float64(hits) / (decoratorSubsamples * decoratorSubsamples)
+			alpha := float64(ca) / 0xffff * coverage
+			dst := img.RGBAAt(x, y)
+			blend := func(d uint8, s uint32) uint8 {
+				return uint8(float64(s)/0x101*coverage + float64(d)*(1-alpha) + 0.5)
+			}
+			img.SetRGBA(x, y, color.RGBA{
+				R: blend(dst.R, cr),
+				G: blend(dst.G, cg),
+				B: blend(dst.B, cb),
+				A: blend(dst.A, ca),
+			})
+		}
+	}
+}
+
+// key returns a string identifying the ring for style keys.
+func (r *progressRing) key() string {
+	if r == nil {
+		return ""
+	}
+	return "-ring" + strconv.FormatFloat(r.fraction, 'g', -1, 64) + paletteKey([]color.Color{r.color})
+}
+
+// Size of the count badge relative to the avatar dimension.
+const (
+	badgeHeight     = 0.36
+	badgeTextHeight = 0.55
+	badgeMaxCount   = 99
+)
+
+// BadgeStyle defines the placement and colors of a count badge.
+// Zero valued colors default to white text on red.
+type BadgeStyle struct {
+	Corner     Corner
+	Background color.Color
+	Foreground color.Color
+}
+
+// countBadge is a count bubble drawn in a corner of the avatar.
+type countBadge struct {
+	count int
+	style BadgeStyle
+}
+
+// WithCountBadge draws a bubble showing n, e.g. an unread count or a team size, in a corner of the avatar.
+// Counts above 99 are shown as "99+" and no badge is drawn for counts of 0 or less.
+func WithCountBadge(n int, style BadgeStyle) func(a *Avatar) {
+	if style.Background == nil {
+		style.Background = color.RGBA{0xE5, 0x39, 0x35, 0xff}
+	}
+	if style.Foreground == nil {
+		style.Foreground = color.White
+	}
+	return func(a *Avatar) {
+		a.countBadge = &countBadge{count: n, style: style}
+	}
+}
+
+// draw renders the badge onto img, sizing the text to fit the bubble.
+func (b *countBadge) draw(img *image.RGBA) {
+	if b.count <= 0 {
+		return
+	}
+	text := strconv.Itoa(b.count)
+	if b.count > badgeMaxCount {
+		text = strconv.Itoa(badgeMaxCount) + "+"
+	}
+
+	bounds := img.Bounds()
+	size := bounds.Dx()
+	height := int(float64(size)*badgeHeight + 0.5)
+	scale := fitTextScale(text, size, int(float64(height)*badgeTextHeight))
+	width := textWidth(text, scale) + height - textHeight(scale)
+	if width < height {
+		width = height
+	}
+
+	rect := image.Rect(size-width, 0, size, height)
+	switch b.style.Corner {
+	case CORNER_BOTTOM_RIGHT:
+		rect = image.Rect(size-width, size-height, size, size)
+	case CORNER_BOTTOM_LEFT:
+		rect = image.Rect(0, size-height, width, size)
+	case CORNER_TOP_LEFT:
+		rect = image.Rect(0, 0, width, height)
+	}
+	rect = rect.Add(bounds.Min)
+
+	radius := float64(height) / 2
+	drawCoverage(img, b.style.Background, func(x, y float64) bool {
+		x, y = x+float64(bounds.Min.X), y+float64(bounds.Min.Y)
+		left, right := float64(rect.Min.X)+radius, float64(rect.Max.X)-radius
+		cx := math.Max(left, math.Min(right, x))
+		return math.Hypot(x-cx, y-float64(rect.Min.Y)-radius) <= radius
+	})
+	drawText(img, text,
+		rect.Min.X+(rect.Dx()-textWidth(text, scale))/2,
+		rect.Min.Y+(rect.Dy()-textHeight(scale))/2,
+		scale, b.style.Foreground)
+}
+
+// key returns a string identifying the badge for style keys.
+func (b *countBadge) key() string {
+	if b == nil {
+		return ""
+	}
+	return "-badge" + strconv.Itoa(b.count) + "-" + strconv.Itoa(int(b.style.Corner)) +
+		paletteKey([]color.Color{b.style.Background, b.style.Foreground})
+}
@@ -0,0 +1,199 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultLocale is the locale used by DescribePattern unless WithLocale selects another one.
+const defaultLocale = "en"
+
+// Thresholds of the filled share of cells used to classify patterns.
+const (
+	sparseShare   = 0.35
+	denseShare    = 0.65
+	emphasisShare = 0.25
+)
+
+// PatternDescription is what DescribePattern knows about a pattern, for locales to put into words.
+type PatternDescription struct {
+	// Color is the name of the color of the filled cells.
+	Color ColorName
+	// Symmetric reports whether the pattern is mirror symmetric about Axis.
+	Symmetric bool
+	Axis      Axis
+	// Size is the number of cells per row and column.
+	Size int
+	// Density classifies the share of filled cells.
+	Density Density
+	// Emphasis tells whether filled cells gather in the center or at the edges of the pattern.
+	Emphasis Emphasis
+}
+
+// LocaleFunc turns a pattern description into text in one language.
+type LocaleFunc func(d PatternDescription) string
+
+// localeMu guards locales against concurrent registrations.
+var localeMu sync.RWMutex
+
+var locales = map[string]LocaleFunc{
+	defaultLocale: describeEnglish,
+}
+
+// RegisterLocale makes a language selectable with WithLocale, replacing any locale registered as tag before.
+func RegisterLocale(tag string, fn LocaleFunc) {
+	localeMu.Lock()
+	defer localeMu.Unlock()
+	locales[tag] = fn
+}
+
+// WithLocale sets the language of DescribePattern to a locale registered with RegisterLocale.
+// The default is "en".
+func WithLocale(tag string) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.locale = tag
+	}
+}
+
+// DescribePattern returns a short description of the avatar's pixel pattern, such as
+// "blue symmetric 5x5 pattern, balanced fill, dense center", e.g. as alt text for the avatar image.
+func (av *Avatar) DescribePattern() (string, error) {
+	localeMu.RLock()
+	describe, ok := locales[av.locale]
+	localeMu.RUnlock()
+	if !ok {
+		return "", av.wrapErr(ErrInvalidOption, "describe pattern", fmt.Errorf("%w: %q", ErrUnknownLocale, av.locale))
+	}
+	av, err := av.prepare()
+	if err != nil {
+		return "", err
+	}
+	pattern, err := av.patternImage()
+	if err != nil {
+		return "", err
+	}
+
+	d := PatternDescription{Size: int(av.pixelPattern)}
+	fill, _ := av.patternColors(sha256.Sum256([]byte(av.value)))
+	d.Color = colorName(fill)
+	if CheckSymmetry(pattern, AXIS_VERTICAL) == nil {
+		d.Symmetric, d.Axis = true, AXIS_VERTICAL
+	} else if CheckSymmetry(pattern, AXIS_HORIZONTAL) == nil {
+		d.Symmetric, d.Axis = true, AXIS_HORIZONTAL
+	}
+
+	var filled, edgeFilled, edgeCells int
+	for y := 0; y < d.Size; y++ {
+		for x := 0; x < d.Size; x++ {
+			edge := x == 0 || y == 0 || x == d.Size-1 || y == d.Size-1
+			isFilled := sameColor(pattern.At(x, y), fill)
+			if edge {
+				edgeCells++
+			}
+			if isFilled {
+				filled++
+				if edge {
+					edgeFilled++
+				}
+			}
+		}
+	}
+	share := float64(filled) / float64(d.Size*d.Size)
+	switch {
+	case share < sparseShare:
+		d.Density = DENSITY_SPARSE
+	case share > denseShare:
+		d.Density = DENSITY_DENSE
+	default:
+		d.Density = DENSITY_BALANCED
+	}
+	edgeShare := float64(edgeFilled) / float64(edgeCells)
+	centerShare := float64(filled-edgeFilled) / float64(d.Size*d.Size-edgeCells)
+	switch {
+	case centerShare-edgeShare > emphasisShare:
+		d.Emphasis = EMPHASIS_CENTER
+	case edgeShare-centerShare > emphasisShare:
+		d.Emphasis = EMPHASIS_EDGES
+	}
+
+	return describe(d), nil
+}
+
+// LocaleNames returns the tags of the registered locales in alphabetical order.
+func LocaleNames() []string {
+	localeMu.RLock()
+	defer localeMu.RUnlock()
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// colorName returns the name of the hue of c, or COLOR_GRAY for colors with little saturation.
+func colorName(c color.Color) ColorName {
+	h, s, l := toHSL(c)
+	if s < 0.15 || l < 0.08 || l > 0.92 {
+		return COLOR_GRAY
+	}
+	switch {
+	case h < 15 || h >= 345:
+		return COLOR_RED
+	case h < 45:
+		return COLOR_ORANGE
+	case h < 70:
+		return COLOR_YELLOW
+	case h < 160:
+		return COLOR_GREEN
+	case h < 200:
+		return COLOR_CYAN
+	case h < 255:
+		return COLOR_BLUE
+	case h < 290:
+		return COLOR_PURPLE
+	default:
+		return COLOR_PINK
+	}
+}
+
+var englishColorNames = map[ColorName]string{
+	COLOR_GRAY:   "gray",
+	COLOR_RED:    "red",
+	COLOR_ORANGE: "orange",
+	COLOR_YELLOW: "yellow",
+	COLOR_GREEN:  "green",
+	COLOR_CYAN:   "cyan",
+	COLOR_BLUE:   "blue",
+	COLOR_PURPLE: "purple",
+	COLOR_PINK:   "pink",
+}
+
+var englishDensities = map[Density]string{
+	DENSITY_SPARSE:   "sparse fill",
+	DENSITY_BALANCED: "balanced fill",
+	DENSITY_DENSE:    "dense fill",
+}
+
+// describeEnglish is the default locale.
+func describeEnglish(d PatternDescription) string {
+	var b strings.Builder
+	b.WriteString(englishColorNames[d.Color])
+	if d.Symmetric {
+		b.WriteString(" symmetric")
+	}
+	b.WriteString(" " + strconv.Itoa(d.Size) + "x" + strconv.Itoa(d.Size) + " pattern, ")
+	b.WriteString(englishDensities[d.Density])
+	switch d.Emphasis {
+	case EMPHASIS_CENTER:
+		b.WriteString(", dense center")
+	case EMPHASIS_EDGES:
+		b.WriteString(", dense edges")
+	}
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package avatar
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"regexp"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// Defaults of digest avatars.
+const (
+	// digestIconSize suits the layer and tag lists of registry UIs.
+	digestIconSize = 32
+	// shortDigestLength is the number of encoded digest characters on digest cards, like short image IDs.
+	shortDigestLength = 12
+)
+
+// digestPattern matches OCI content digests, an algorithm like "sha256" and the encoded hash.
+var digestPattern = regexp.MustCompile(`^[a-z0-9]+(?:[+._-][a-z0-9]+)*:[a-zA-Z0-9=_-]+$`)
+
+// digestLengths are the lengths of the hex encoded hashes of the registered OCI digest algorithms.
+var digestLengths = map[string]int{"sha256": 64, "sha512": 128}
+
+// NormalizeDigest checks an OCI content digest like "sha256:6c3c62...", with surrounding whitespace
+// removed, and returns it with the hash of the registered sha256 and sha512 algorithms in lower case.
+func NormalizeDigest(digest string) (string, error) {
+	digest = strings.TrimSpace(digest)
+	if !digestPattern.MatchString(digest) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDigest, digest)
+	}
+	algorithm, encoded, _ := strings.Cut(digest, ":")
+	if length, ok := digestLengths[algorithm]; ok {
+		encoded = strings.ToLower(encoded)
+		if len(encoded) != length || strings.Trim(encoded, "0123456789abcdef") != "" {
+			return "", fmt.Errorf("%w: %s hash of %q is not %d hex digits", ErrInvalidDigest, algorithm, digest, length)
+		}
+	}
+	return algorithm + ":" + encoded, nil
+}
+
+// NewDigest creates a 32px Avatar for the normalized OCI digest of a container image or layer, see
+// NormalizeDigest. The options may change the dimension. Generate returns an error wrapping
+// ErrInvalidDigest if digest is invalid.
+func NewDigest(digest string, opts ...CreateOption) *Avatar {
+	opts = append([]CreateOption{WithDimension(digestIconSize)}, opts...)
+	value, err := NormalizeDigest(digest)
+	if err != nil {
+		av := New(digest, opts...)
+		av.err = err
+		return av
+	}
+	return New(value, opts...)
+}
+
+// GenerateDigestCard creates a compact card showing the avatar of an OCI digest beside the digest
+// shortened to 12 hash characters, e.g. "sha256:6c3c624b58db", for registry UIs. The card is as high
+// as the avatar and as wide as needed for the text. The options apply to the avatar as in NewDigest.
+func GenerateDigestCard(digest string, opts ...CreateOption) (*AvatarResult, error) {
+	card := NewDigest(digest, opts...)
+	if err := card.validate(); err != nil {
+		return nil, err
+	}
+	size := card.outputDimension()
+	icon, err := card.renderMember(card.value, size)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm, encoded, _ := strings.Cut(card.value, ":")
+	label := algorithm + ":" + encoded[:min(len(encoded), shortDigestLength)]
+	padding := max(size/8, 1)
+	scale := fitTextScale(label, 8*size, size/2)
+	background, foreground := getBackgroundColor(card.darkMode), color.Color(color.Black)
+	if card.darkMode {
+		foreground = color.White
+	}
+	card.image = image.NewRGBA(image.Rect(0, 0, size+2*padding+textWidth(label, scale), size))
+	draw.Draw(card.image, card.image.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(card.image, image.Rect(0, 0, size, size), icon.image, image.Point{}, draw.Src)
+	drawText(card.image, label, size+padding, (size-textHeight(scale))/2, scale, foreground)
+
+	return card.output()
+}
@@ -0,0 +1,50 @@
+package avatar
+
+import "image/color"
+
+// DominantColors returns up to n colors of the rendered image, ordered from the most to the least
+// used, so interfaces can derive accent colors such as borders, gradients and toast backgrounds that
+// match the avatar. Transparent pixels are not counted.
+func (r *AvatarResult) DominantColors(n int) []color.Color {
+	if n <= 0 || r.pixels == nil {
+		return nil
+	}
+	counts := make(map[color.RGBA]int)
+	bounds := r.pixels.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if c := r.pixels.RGBAAt(x, y); c.A > 0 {
+				counts[c]++
+			}
+		}
+	}
+	colors := byCount(counts)
+	dominant := make([]color.Color, min(n, len(colors)))
+	for i := range dominant {
+		dominant[i] = colors[i]
+	}
+	return dominant
+}
+
+// minTextContrast is the WCAG AA contrast ratio for normal text.
+const minTextContrast = 4.5
+
+// SuggestTextColor returns a color for text rendered on the dominant color of the avatar, such as a
+// username on an avatar-derived background: the first of palette with a contrast ratio of at least
+// 4.5:1, the WCAG AA level for normal text, or else black or white, whichever contrasts better.
+// Transparent parts of the dominant color are composited over white.
+func (r *AvatarResult) SuggestTextColor(palette ...color.Color) color.Color {
+	background := color.Color(color.White)
+	if dominant := r.DominantColors(1); len(dominant) > 0 {
+		background = overWhite(dominant[0])
+	}
+	for _, c := range palette {
+		if contrastRatio(c, background) >= minTextContrast {
+			return c
+		}
+	}
+	if luminance(background) > contrastLuminance {
+		return color.Black
+	}
+	return color.White
+}
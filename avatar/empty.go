@@ -0,0 +1,33 @@
+package avatar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithEmptyValuePolicy sets how avatars for empty or whitespace-only values are generated, which
+// usually come from missing user names or unset form fields rather than real identities.
+// EMPTY_VALUE_ERROR, the default, makes Generate return ErrEmptyValue, EMPTY_VALUE_HASH renders the
+// avatar of the value like any other and EMPTY_VALUE_PLACEHOLDER renders a placeholder photo, see
+// WithPlaceholderPhoto, so missing values are recognizable as such.
+func WithEmptyValuePolicy(policy EmptyValuePolicy) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.emptyValuePolicy = policy
+	}
+}
+
+// checkValue applies the empty value policy. It returns an error wrapping ErrEmptyValue for
+// empty values with EMPTY_VALUE_ERROR and switches to a placeholder photo with EMPTY_VALUE_PLACEHOLDER.
+func (av *Avatar) checkValue() error {
+	if strings.TrimSpace(av.value) != "" {
+		return nil
+	}
+	switch av.emptyValuePolicy {
+	case EMPTY_VALUE_HASH:
+		return nil
+	case EMPTY_VALUE_PLACEHOLDER:
+		av.placeholderPhoto = true
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrEmptyValue, av.value)
+}
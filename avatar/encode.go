@@ -0,0 +1,157 @@
+package avatar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+)
+
+// pngHeaderLen is the length of the PNG signature followed by the IHDR chunk.
+const pngHeaderLen = 8 + 4 + 4 + 13 + 4
+
+// pngIENDLen is the length of the IEND chunk ending every PNG.
+const pngIENDLen = 4 + 4 + 4
+
+// defaultDPI is the pixel density assumed by most design tools for images without density metadata.
+const defaultDPI = 72
+
+// encode writes the rendered image to w in the configured format.
+func (av *Avatar) encode(w io.Writer) error {
+	switch av.format {
+	case FORMAT_SVG:
+		return av.writeSVG(w)
+	case FORMAT_HTML:
+		return av.writeHTMLTable(w)
+	}
+	if av.scanlines != nil {
+		return encodeScanlines(w, av.scanlines, av.density())
+	}
+	if av.format == FORMAT_JPEG && av.jpegQuality != 0 {
+		return encodeJPEG(w, av.image, av.density(), func(w io.Writer, img image.Image) error {
+			return jpeg.Encode(w, img, &jpeg.Options{Quality: av.jpegQuality})
+		})
+	}
+	if e, _ := lookupEncoder(av.format); e.encode != nil {
+		if av.format == FORMAT_JPEG {
+			return encodeJPEG(w, av.image, av.density(), e.encode)
+		}
+		return e.encode(w, av.image)
+	}
+	if av.maxBytes > 0 {
+		data, err := av.encodeWithinBudget()
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	return encodePNG(w, av.image, av.density(), png.DefaultCompression)
+}
+
+// encodeBytes returns the encoded rendered image. Returned errors wrap ErrEncode.
+func (av *Avatar) encodeBytes() ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	av.phase("encode", &av.timings.Encode, func() {
+		err = av.encode(&buf)
+	})
+	if err != nil {
+		return nil, av.wrapErr(ErrEncode, "encode "+av.format.String(), err)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodePNG writes img to w as PNG, embedding the density in dots per inch unless it is 0.
+func encodePNG(w io.Writer, img image.Image, density float64, level png.CompressionLevel) error {
+	encoder := png.Encoder{CompressionLevel: level}
+	if density == 0 {
+		return encoder.Encode(w, img)
+	}
+	var buf bytes.Buffer
+	if err := encoder.Encode(&buf, img); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	if _, err := w.Write(data[:pngHeaderLen]); err != nil {
+		return err
+	}
+	if _, err := w.Write(physChunk(density)); err != nil {
+		return err
+	}
+	_, err := w.Write(data[pngHeaderLen:])
+	return err
+}
+
+// encodeJPEG writes img to w as JPEG with encode, embedding the density in dots per inch in a JFIF
+// APP0 segment unless it is 0 or the encoder already wrote an APP0 segment.
+func encodeJPEG(w io.Writer, img image.Image, density float64, encode EncoderFunc) error {
+	if density == 0 {
+		return encode(w, img)
+	}
+	var buf bytes.Buffer
+	if err := encode(&buf, img); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 || data[2] == 0xff && data[3] == 0xe0 {
+		_, err := w.Write(data)
+		return err
+	}
+	if _, err := w.Write(data[:2]); err != nil {
+		return err
+	}
+	if _, err := w.Write(jfifSegment(density)); err != nil {
+		return err
+	}
+	_, err := w.Write(data[2:])
+	return err
+}
+
+// jfifSegment returns a JPEG APP0 segment with a JFIF 1.02 header declaring the given density in dots per inch.
+func jfifSegment(dpi float64) []byte {
+	density := uint16(min(math.Round(dpi), math.MaxUint16))
+	segment := []byte{0xff, 0xe0, 0, 16, 'J', 'F', 'I', 'F', 0, 1, 2, 1, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(segment[12:], density)
+	binary.BigEndian.PutUint16(segment[14:], density)
+	return segment
+}
+
+// density returns the pixel density of the output image in dots per inch,
+// or 0 if no density metadata should be embedded.
+func (av *Avatar) density() float64 {
+	if av.dpi <= 0 && av.scaleFactor == 1 {
+		return 0
+	}
+	dpi := av.dpi
+	if dpi <= 0 {
+		dpi = defaultDPI
+	}
+	return float64(dpi) * av.scaleFactor
+}
+
+// densityKey returns a style key part for the embedded density, empty if no density is embedded.
+func densityKey(dpi float64) string {
+	if dpi == 0 {
+		return ""
+	}
+	return "-dpi" + strconv.FormatFloat(dpi, 'g', -1, 64)
+}
+
+// physChunk returns a PNG pHYs chunk declaring the given density in dots per inch.
+func physChunk(dpi float64) []byte {
+	ppm := uint32(math.Round(dpi / 0.0254))
+	chunk := make([]byte, 4+4+9+4)
+	binary.BigEndian.PutUint32(chunk[0:], 9)
+	copy(chunk[4:], "pHYs")
+	binary.BigEndian.PutUint32(chunk[8:], ppm)
+	binary.BigEndian.PutUint32(chunk[12:], ppm)
+	chunk[16] = 1 // unit: meter
+	binary.BigEndian.PutUint32(chunk[17:], crc32.ChecksumIEEE(chunk[4:17]))
+	return chunk
+}
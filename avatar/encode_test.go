@@ -0,0 +1,38 @@
+package avatar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/jpeg"
+	"testing"
+)
+
+func TestJPEGDensity(t *testing.T) {
+	for _, opts := range [][]CreateOption{
+		{WithDPI(300)},
+		{WithDPI(300), WithJPEGQuality(50)},
+	} {
+		result, err := New("octocat", append(opts, WithFormat(FORMAT_JPEG), WithOutputType(OUTPUT_BUFFER))...).Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := result.Buffer.Bytes()
+		if len(data) < 18 || !bytes.Equal(data[2:4], []byte{0xff, 0xe0}) || string(data[6:11]) != "JFIF\x00" {
+			t.Fatalf("no JFIF APP0 segment after SOI: % x", data[:min(len(data), 20)])
+		}
+		if unit, x, y := data[13], binary.BigEndian.Uint16(data[14:]), binary.BigEndian.Uint16(data[16:]); unit != 1 || x != 300 || y != 300 {
+			t.Errorf("got unit %d, density %dx%d, want 300 dots per inch", unit, x, y)
+		}
+		if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+	}
+
+	result, err := New("octocat", WithFormat(FORMAT_JPEG), WithOutputType(OUTPUT_BUFFER)).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := result.Buffer.Bytes(); bytes.Equal(data[2:4], []byte{0xff, 0xe0}) {
+		t.Error("JFIF APP0 segment written without density")
+	}
+}
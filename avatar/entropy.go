@@ -0,0 +1,63 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+)
+
+// entropySplit is the number of value hash bits feeding the pattern and the colors.
+type entropySplit struct {
+	patternBits, colorBits int
+}
+
+// WithEntropySplit derives the pattern only from the first patternBits bits of the value hash and
+// the colors only from the following colorBits bits, at most 256 bits in total. Security sensitive
+// uses such as key fingerprint verification spend most bits on the pattern, while a larger color
+// share makes neighboring avatars in a list easier to tell apart. The pattern itself holds at most
+// one bit per cell of its left half, and PRNG_MATH_RAND seeds from 32 bits only.
+func WithEntropySplit(patternBits, colorBits int) func(a *Avatar) {
+	return func(a *Avatar) {
+		if patternBits < 0 || colorBits < 0 || patternBits+colorBits > sha256.Size*8 {
+			a.err = fmt.Errorf("%w: %d pattern and %d color bits", ErrInvalidEntropySplit, patternBits, colorBits)
+			return
+		}
+		a.entropySplit = &entropySplit{patternBits, colorBits}
+	}
+}
+
+// patternHash returns the hash the pattern is drawn from.
+func (s *entropySplit) patternHash(hash [sha256.Size]byte) [sha256.Size]byte {
+	if s == nil {
+		return hash
+	}
+	return truncatedHash(hash, "pattern", 0, s.patternBits)
+}
+
+// colorHash returns the hash the colors are derived from.
+func (s *entropySplit) colorHash(hash [sha256.Size]byte) [sha256.Size]byte {
+	if s == nil {
+		return hash
+	}
+	return truncatedHash(hash, "color", s.patternBits, s.colorBits)
+}
+
+// truncatedHash expands the n bits of hash starting at bit offset into a full hash for the given use.
+func truncatedHash(hash [sha256.Size]byte, use string, offset, n int) [sha256.Size]byte {
+	var bits [sha256.Size]byte
+	for i := 0; i < n; i++ {
+		if bit := offset + i; hash[bit/8]&(0x80>>(bit%8)) != 0 {
+			bits[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	var buf [2 * sha256.Size]byte
+	return sha256.Sum256(append(append(buf[:0], use...), bits[:]...))
+}
+
+// key returns the part of the style key for the split.
+func (s *entropySplit) key() string {
+	if s == nil {
+		return ""
+	}
+	return "-bits" + strconv.Itoa(s.patternBits) + "/" + strconv.Itoa(s.colorBits)
+}
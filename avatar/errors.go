@@ -1,7 +1,70 @@
 package avatar
 
-import "errors"
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// Error categories. Every error returned while generating an avatar wraps one of them,
+// next to the more specific sentinel or underlying error, so callers can tell invalid
+// configuration from encoding failures, failures to write the output (e.g. a full disk)
+// and registered algorithms that panicked.
+var (
+	ErrInvalidOption  = errors.New("invalid option")
+	ErrEncode         = errors.New("encode avatar")
+	ErrWrite          = errors.New("write avatar")
+	ErrAlgorithmPanic = errors.New("algorithm panicked")
+)
 
 var (
 	ErrUnknownOutputType = errors.New("unknown output type")
+	ErrNoValues          = errors.New("no values given")
+	ErrUnknownPalette    = errors.New("unknown palette")
+	ErrEmptyPalette      = errors.New("empty palette")
+	ErrMaxBytesExceeded  = errors.New("avatar does not fit into the byte budget")
+	ErrInvalidColor      = errors.New("invalid color, expected #RRGGBB")
+	ErrInvalidColorBands = errors.New("invalid color bands")
+	ErrInvalidColorRange = errors.New("invalid color range, expected 0 <= min <= max <= 1")
+	ErrInvalidDimension  = errors.New("invalid dimension")
+	ErrUnknownLocale     = errors.New("unknown locale")
+	ErrPatternTooLarge   = errors.New("pattern does not fit")
+
+	ErrUnknownAlgorithm       = errors.New("unknown algorithm")
+	ErrInvalidAlgorithmParams = errors.New("invalid algorithm parameters")
+
+	ErrNotSymmetric      = errors.New("image is not symmetric")
+	ErrColorNotInPalette = errors.New("color is not in the palette")
+	ErrNotDeterministic  = errors.New("algorithm is not deterministic")
+
+	ErrInvalidMeshOptions = errors.New("invalid mesh options")
+	ErrInvalidBannerSize  = errors.New("invalid banner size")
+
+	ErrInvalidSupersampling = errors.New("invalid supersampling factor, expected 1 to 8")
+	ErrInvalidEntropySplit  = errors.New("invalid entropy split, expected at most 256 bits")
+	ErrInvalidHost          = errors.New("invalid IP address, prefix or host name")
+	ErrInvalidDigest        = errors.New("invalid OCI digest")
+	ErrEncoderUnavailable   = errors.New("encoder unavailable")
+	ErrQRContentTooLong     = errors.New("content does not fit into a QR code")
+	ErrVectorMismatch       = errors.New("output does not match the test vector")
+	ErrInvalidTTL           = errors.New("invalid TTL, expected a positive duration")
+	ErrInvalidQuota         = errors.New("invalid disk quota")
+	ErrInvalidParallelism   = errors.New("invalid parallelism, expected at least 1")
+	ErrUnknownChecksum      = errors.New("unknown checksum")
+	ErrNoProvenance         = errors.New("avatar carries no provenance")
+	ErrInvalidProvenance    = errors.New("invalid provenance")
+	ErrEmptyValue           = errors.New("empty value")
+	ErrInvalidJPEGQuality   = errors.New("invalid JPEG quality, expected 1 to 100")
+	ErrInvalidCacheLimit    = errors.New("invalid memory cache limit, expected at least 0")
+	ErrInvalidOutputDir     = errors.New("invalid output directory")
+	ErrInvalidFrameCount    = errors.New("invalid frame count")
+	ErrInvalidHapticUnit    = errors.New("invalid haptic unit, expected a positive duration")
+	ErrRenderPanic          = errors.New("render of a shared avatar panicked")
 )
+
+// wrapErr wraps err with its category, the failed operation and the hash prefix of the value,
+// which identifies the avatar without leaking the value into logs.
+func (av *Avatar) wrapErr(category error, op string, err error) error {
+	hash := sha256.Sum256([]byte(av.value))
+	return fmt.Errorf("%w: %s (value %x): %w", category, op, hash[:4], err)
+}
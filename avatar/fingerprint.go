@@ -0,0 +1,65 @@
+package avatar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png"
+	"io"
+)
+
+// Fingerprint returns the hex encoded SHA-256 hash of the dimensions and non-premultiplied pixels of img.
+// Unlike a hash of the file, it does not depend on how the image was encoded or compressed.
+func Fingerprint(img image.Image) string {
+	h := sha256.New()
+	bounds := img.Bounds()
+	var buf [8]byte
+	binary.BigEndian.PutUint32(buf[0:], uint32(bounds.Dx()))
+	binary.BigEndian.PutUint32(buf[4:], uint32(bounds.Dy()))
+	h.Write(buf[:])
+	row := make([]byte, 0, 4*bounds.Dx())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row = row[:0]
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			row = append(row, c.R, c.G, c.B, c.A)
+		}
+		h.Write(row)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify reports whether the image read from r matches the avatar the current configuration
+// generates for the value, e.g. to detect stale or tampered avatar files.
+// Images are compared pixel by pixel, so avatars that were re-encoded or recompressed still match.
+// Data that cannot be decoded as an image does not match; only read errors are returned.
+func (av *Avatar) Verify(r io.Reader) (bool, error) {
+	av, err := av.prepare()
+	if err != nil {
+		return false, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return false, fmt.Errorf("read stored avatar: %w", err)
+	}
+	stored, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return false, nil
+	}
+	if err := av.render(); err != nil {
+		return false, err
+	}
+	expected, err := av.encodeBytes()
+	if err != nil {
+		return false, err
+	}
+	generated, _, err := image.Decode(bytes.NewReader(expected))
+	if err != nil {
+		return false, av.wrapErr(ErrEncode, "decode generated png", err)
+	}
+	return Fingerprint(stored) == Fingerprint(generated), nil
+}
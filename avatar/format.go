@@ -0,0 +1,185 @@
+package avatar
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EncoderFunc writes a rendered avatar to w in an image format.
+type EncoderFunc func(w io.Writer, img image.Image) error
+
+// encoder is a registered image format.
+type encoder struct {
+	mimeType  string
+	extension string
+	// encode is nil for PNG, which encodePNG writes with density metadata and within byte budgets,
+	// and for SVG and HTML, which are drawn from the pixel pattern.
+	encode EncoderFunc
+}
+
+// encoderMu guards encoders against concurrent registrations.
+var encoderMu sync.RWMutex
+
+// encoders are the registered image formats. Only SVG, HTML and the formats of the standard library are built in.
+var encoders = map[Format]encoder{
+	FORMAT_PNG:  {"image/png", ".png", nil},
+	FORMAT_SVG:  {"image/svg+xml", ".svg", nil},
+	FORMAT_HTML: {"text/html; charset=utf-8", ".html", nil},
+	FORMAT_JPEG: {"image/jpeg", ".jpg", func(w io.Writer, img image.Image) error {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: defaultJPEGQuality})
+	}},
+	FORMAT_GIF: {"image/gif", ".gif", func(w io.Writer, img image.Image) error {
+		return gif.Encode(w, img, nil)
+	}},
+}
+
+// RegisterEncoder makes an image format selectable with WithFormat, replacing any encoder registered
+// for format before. Encoders with heavy dependencies, e.g. for FORMAT_WEBP or FORMAT_AVIF, belong in
+// separate modules that register them in an init function, so programs import only the formats they
+// use and this module stays free of cgo and large dependencies:
+//
+//	import _ "example.com/godenticon-webp" // registers FORMAT_WEBP
+func RegisterEncoder(format Format, mimeType, extension string, encode EncoderFunc) {
+	encoderMu.Lock()
+	defer encoderMu.Unlock()
+	encoders[format] = encoder{mimeType, extension, encode}
+}
+
+// lookupEncoder returns the encoder registered for format.
+func lookupEncoder(format Format) (encoder, bool) {
+	encoderMu.RLock()
+	defer encoderMu.RUnlock()
+	e, ok := encoders[format]
+	return e, ok
+}
+
+// Formats returns the formats with a registered encoder in ascending order.
+func Formats() []Format {
+	encoderMu.RLock()
+	defer encoderMu.RUnlock()
+	formats := make([]Format, 0, len(encoders))
+	for format := range encoders {
+		formats = append(formats, format)
+	}
+	sort.Slice(formats, func(i, j int) bool { return formats[i] < formats[j] })
+	return formats
+}
+
+// MIMEType returns the media type of the format, e.g. "image/png", or "" if no encoder is registered.
+func (f Format) MIMEType() string {
+	e, _ := lookupEncoder(f)
+	return e.mimeType
+}
+
+// Extension returns the file extension of the format including the dot, e.g. ".jpg", or "" if no
+// encoder is registered.
+func (f Format) Extension() string {
+	e, _ := lookupEncoder(f)
+	return e.extension
+}
+
+// formatNames are the names of the known formats.
+var formatNames = map[Format]string{
+	FORMAT_PNG:  "png",
+	FORMAT_JPEG: "jpeg",
+	FORMAT_GIF:  "gif",
+	FORMAT_WEBP: "webp",
+	FORMAT_AVIF: "avif",
+	FORMAT_SVG:  "svg",
+	FORMAT_HTML: "html",
+}
+
+// String returns the name of the format, e.g. "png".
+func (f Format) String() string {
+	if name, ok := formatNames[f]; ok {
+		return name
+	}
+	return "format(" + strconv.Itoa(int(f)) + ")"
+}
+
+// WithFormat sets the image format avatars are encoded in, FORMAT_PNG by default. Generate returns
+// an EncoderUnavailableError if no encoder is registered for the format, see RegisterEncoder, unless
+// WithFormatFallback is set. WithMaxBytes applies to PNG only, WithDPI to PNG and JPEG.
+func WithFormat(format Format) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.format = format
+	}
+}
+
+// defaultJPEGQuality is the quality of JPEG avatars without WithJPEGQuality.
+const defaultJPEGQuality = 90
+
+// WithJPEGQuality sets the quality of JPEG avatars from 1 to 100, 90 by default, trading file size
+// for fewer compression artifacts. It encodes with the standard library encoder, also if another
+// encoder is registered for FORMAT_JPEG. Generate returns ErrInvalidJPEGQuality for other qualities.
+func WithJPEGQuality(quality int) func(a *Avatar) {
+	return func(a *Avatar) {
+		if quality < 1 || quality > 100 {
+			a.err = fmt.Errorf("%w: %d", ErrInvalidJPEGQuality, quality)
+			return
+		}
+		a.jpegQuality = quality
+	}
+}
+
+// jpegQualityKey returns a style key part for the JPEG quality, empty for the default.
+func jpegQualityKey(quality int) string {
+	if quality == 0 {
+		return ""
+	}
+	return "-q" + strconv.Itoa(quality)
+}
+
+// WithFormatFallback encodes avatars as PNG if no encoder is registered for the format set by
+// WithFormat, e.g. because the module providing it is not imported. The optional warn function
+// is called with the EncoderUnavailableError before falling back, e.g. to log it.
+func WithFormatFallback(warn func(err error)) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.formatFallback = true
+		a.formatWarning = warn
+	}
+}
+
+// EncoderUnavailableError reports a format without a registered encoder together with the formats
+// that are available. It matches ErrEncoderUnavailable with errors.Is.
+type EncoderUnavailableError struct {
+	Format    Format
+	Available []Format
+}
+
+func (e *EncoderUnavailableError) Error() string {
+	names := make([]string, len(e.Available))
+	for i, format := range e.Available {
+		names[i] = format.String()
+	}
+	return fmt.Sprintf("%s: no %s encoder registered, available formats are %s",
+		ErrEncoderUnavailable, e.Format, strings.Join(names, ", "))
+}
+
+func (e *EncoderUnavailableError) Is(target error) bool {
+	return target == ErrEncoderUnavailable
+}
+
+// checkFormat returns an EncoderUnavailableError if no encoder is registered for the format, or
+// switches to PNG if WithFormatFallback is set.
+func (av *Avatar) checkFormat() error {
+	if _, ok := lookupEncoder(av.format); ok {
+		return nil
+	}
+	err := &EncoderUnavailableError{Format: av.format, Available: Formats()}
+	if !av.formatFallback {
+		return err
+	}
+	if av.formatWarning != nil {
+		av.formatWarning(err)
+	}
+	av.format = FORMAT_PNG
+	return nil
+}
@@ -0,0 +1,130 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"math"
+	"math/rand"
+)
+
+// morphStagger is the fraction of a morph during which cells start their transition.
+const morphStagger = 0.5
+
+// FrameFunc receives the frames of an animation in order.
+// Frames must not be modified after returning. Returning an error stops the generation.
+type FrameFunc func(index int, frame *image.RGBA) error
+
+// GenerateFrames renders an animation that progressively reveals the avatar's pattern over the given
+// number of frames and passes every frame to fn as soon as it is rendered. The first frame shows only the
+// background and the last one the complete avatar, a single frame shows the complete avatar. Cells appear
+// in an order derived from the value, so the animation is deterministic.
+// Returns an error wrapping ErrInvalidOption if frames is less than 1.
+func (av *Avatar) GenerateFrames(frames int, fn FrameFunc) error {
+	av, err := av.prepare()
+	if err != nil {
+		return err
+	}
+	if frames < 1 {
+		return av.wrapErr(ErrInvalidOption, "check frames", fmt.Errorf("%w: %d", ErrInvalidFrameCount, frames))
+	}
+
+	hash := sha256.Sum256([]byte(av.value))
+	background, err := av.drawPattern(hash)
+	if err != nil {
+		return err
+	}
+	pattern := av.image
+	bounds := pattern.Bounds()
+	cells := bounds.Dx() * bounds.Dy()
+	order := rand.New(newPRNGSource(PRNG_XOSHIRO, hash)).Perm(cells)
+
+	for i := 0; i < frames; i++ {
+		revealed := cells
+		if frames > 1 {
+			revealed = i * cells / (frames - 1)
+		}
+		frame := image.NewRGBA(bounds)
+		for j := 0; j < cells; j++ {
+			x, y := j%bounds.Dx(), j/bounds.Dx()
+			frame.Set(x, y, background)
+		}
+		for _, cell := range order[:revealed] {
+			x, y := cell%bounds.Dx(), cell/bounds.Dx()
+			frame.Set(x, y, pattern.At(x, y))
+		}
+		av.image = frame
+		av.compose(hash, background)
+		if err := fn(i, av.image); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Frames renders the animation of GenerateFrames and returns all of its frames.
+func (av *Avatar) Frames(frames int) ([]image.Image, error) {
+	var images []image.Image
+	err := av.GenerateFrames(frames, func(_ int, frame *image.RGBA) error {
+		images = append(images, frame)
+		return nil
+	})
+	return images, err
+}
+
+// MorphFrames renders a transition from the avatar of a to the avatar of b over the given number of frames
+// and passes every frame to fn. Colors are cross-faded cell by cell, with the cells starting their
+// transition at staggered, deterministic times. The first frame is the avatar of a, the last one that of b.
+// Both values are sanitized and checked like the value of an avatar. Returns an error wrapping
+// ErrInvalidOption if steps is less than 2.
+func MorphFrames(a, b string, steps int, fn FrameFunc, opts ...CreateOption) error {
+	from, to := New(a, opts...), New(b, opts...)
+	if steps < 2 {
+		return from.wrapErr(ErrInvalidOption, "check steps", fmt.Errorf("%w: %d", ErrInvalidFrameCount, steps))
+	}
+	if err := from.validate(); err != nil {
+		return err
+	}
+	if err := to.validate(); err != nil {
+		return err
+	}
+	if err := from.render(); err != nil {
+		return err
+	}
+	if err := to.render(); err != nil {
+		return err
+	}
+
+	bounds := from.image.Bounds()
+	size := bounds.Dx()
+	cells := int(from.pixelPattern)
+	hash := sha256.Sum256([]byte(from.value + "\x00" + to.value))
+	delays := rand.New(newPRNGSource(PRNG_XOSHIRO, hash)).Perm(cells * cells)
+
+	for i := 0; i < steps; i++ {
+		t := float64(i) / float64(steps-1)
+		frame := image.NewRGBA(bounds)
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				delay := float64(delays[(y*cells/size)*cells+x*cells/size]) / float64(cells*cells) * morphStagger
+				local := (t - delay) / (1 - morphStagger)
+				local = math.Max(0, math.Min(1, local))
+				frame.SetRGBA(x, y, lerpRGBA(from.image.RGBAAt(x, y), to.image.RGBAAt(x, y), local))
+			}
+		}
+		if err := fn(i, frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Morph renders the transition of MorphFrames and returns all of its frames.
+func Morph(a, b string, steps int, opts ...CreateOption) ([]image.Image, error) {
+	var images []image.Image
+	err := MorphFrames(a, b, steps, func(_ int, frame *image.RGBA) error {
+		images = append(images, frame)
+		return nil
+	}, opts...)
+	return images, err
+}
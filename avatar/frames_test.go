@@ -0,0 +1,83 @@
+package avatar
+
+import (
+	"errors"
+	"image"
+	"testing"
+)
+
+func TestFrames(t *testing.T) {
+	av := New("octocat", WithOutputType(OUTPUT_BUFFER))
+	for _, n := range []int{0, -1} {
+		if _, err := av.Frames(n); !errors.Is(err, ErrInvalidOption) || !errors.Is(err, ErrInvalidFrameCount) {
+			t.Errorf("Frames(%d): got %v, want ErrInvalidOption wrapping ErrInvalidFrameCount", n, err)
+		}
+	}
+	for _, n := range []int{1, 2, 5} {
+		frames, err := av.Frames(n)
+		if err != nil {
+			t.Fatalf("Frames(%d): %v", n, err)
+		}
+		if len(frames) != n {
+			t.Errorf("Frames(%d): got %d frames", n, len(frames))
+		}
+	}
+
+	single, err := av.Frames(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames, err := av.Frames(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalImages(single[0], frames[2]) {
+		t.Error("Frames(1) does not show the complete avatar")
+	}
+}
+
+func TestMorph(t *testing.T) {
+	for _, steps := range []int{1, 0, -1} {
+		if _, err := Morph("octocat", "hubot", steps); !errors.Is(err, ErrInvalidOption) || !errors.Is(err, ErrInvalidFrameCount) {
+			t.Errorf("Morph with %d steps: got %v, want ErrInvalidOption wrapping ErrInvalidFrameCount", steps, err)
+		}
+	}
+	if _, err := Morph("octocat", "", 3); !errors.Is(err, ErrEmptyValue) {
+		t.Errorf("empty target: got %v, want ErrEmptyValue", err)
+	}
+
+	frames, err := Morph("octocat", "hubot", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 4 {
+		t.Fatalf("got %d frames, want 4", len(frames))
+	}
+	sanitized, err := Morph("octocat", "\uff48\uff55\uff42\uff4f\uff54", 4, WithValueSanitization())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range frames {
+		if !equalImages(frames[i], sanitized[i]) {
+			t.Errorf("frame %d differs for the sanitized target", i)
+		}
+	}
+}
+
+// equalImages reports whether a and b have the same bounds and pixels.
+func equalImages(a, b image.Image) bool {
+	if a.Bounds() != b.Bounds() {
+		return false
+	}
+	bounds := a.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r1, g1, b1, a1 := a.At(x, y).RGBA()
+			r2, g2, b2, a2 := b.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				return false
+			}
+		}
+	}
+	return true
+}
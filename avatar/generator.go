@@ -0,0 +1,326 @@
+package avatar
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default limits of the in-memory cache of Generators, see WithMemoryCacheLimit.
+const (
+	defaultCacheEntries = 10000
+	defaultCacheBytes   = 64 << 20
+)
+
+// Generator renders avatars for arbitrary values using a fixed set of options.
+// Generated images are kept in a bounded in-memory cache, see WithMemoryCacheLimit,
+// and a Generator is safe for concurrent use.
+type Generator struct {
+	opts     []CreateOption
+	style    string
+	theme    func(time.Time) Theme
+	cache    *memoryCache
+	canvases *canvasPool
+	// remote is the ByteCache set by WithByteCache, nil without one, and expiry the TTL of its entries.
+	remote ByteCache
+	expiry time.Duration
+	// policy applies when less than margin is left until the deadline of GenerateContext.
+	policy DeadlinePolicy
+	margin time.Duration
+}
+
+// NewGenerator creates a Generator that applies the given options to every avatar it renders.
+// The output type is always OUTPUT_BUFFER.
+func NewGenerator(opts ...CreateOption) *Generator {
+	config := New("", opts...)
+	return newGenerator(newMemoryCache(config.cacheEntries, config.cacheBytes), newCanvasPool(), opts)
+}
+
+// WithMemoryCacheLimit limits the in-memory cache of Generators, and of the Handlers and CachingFS
+// built on them, to at most entries avatars of together at most bytes encoded bytes. The least
+// recently used avatars are evicted first. The default is 10000 avatars and 64 MiB, zero for either
+// limit turns the cache off. Generators derived with With keep the cache of their parent.
+func WithMemoryCacheLimit(entries int, bytes int64) func(a *Avatar) {
+	return func(a *Avatar) {
+		if entries < 0 || bytes < 0 {
+			a.err = fmt.Errorf("%w: %d entries, %d bytes", ErrInvalidCacheLimit, entries, bytes)
+			return
+		}
+		a.cacheEntries, a.cacheBytes = entries, bytes
+	}
+}
+
+func newGenerator(cache *memoryCache, canvases *canvasPool, opts []CreateOption) *Generator {
+	opts = append(opts[:len(opts):len(opts)], WithOutputType(OUTPUT_BUFFER))
+	av := New("", opts...)
+	return &Generator{
+		opts:     opts,
+		style:    av.styleKey(),
+		theme:    av.themeProvider,
+		cache:    cache,
+		canvases: canvases,
+		remote:   av.byteCache,
+		expiry:   av.byteCacheTTL,
+		policy:   av.deadlinePolicy,
+		margin:   av.deadlineMargin,
+	}
+}
+
+// With returns a Generator applying the given options on top of the options of g.
+// The derived Generator shares the cache and the preallocated canvases of g.
+func (g *Generator) With(opts ...CreateOption) *Generator {
+	return newGenerator(g.cache, g.canvases, append(g.opts[:len(g.opts):len(g.opts)], opts...))
+}
+
+// Style returns a short fingerprint of the options and the current theme of g. Avatars of the same
+// value rendered with the same style are identical, so bulk runs can skip avatars whose recorded
+// style did not change.
+func (g *Generator) Style() string {
+	if g.theme == nil {
+		return styleFingerprint(g.style, nil)
+	}
+	theme := g.theme(time.Now())
+	return styleFingerprint(g.style, &theme)
+}
+
+// Extension returns the file extension of the avatars g encodes including the dot, e.g. ".png", that of
+// FORMAT_PNG if their format is unavailable and WithFormatFallback is set, or "" if it is unavailable.
+func (g *Generator) Extension() string {
+	av := New("", g.opts...)
+	if _, ok := lookupEncoder(av.format); !ok && av.formatFallback {
+		return FORMAT_PNG.Extension()
+	}
+	return av.format.Extension()
+}
+
+// Generate returns the encoded avatar for the given value, rendering it only on a cache miss.
+// With a theme provider, avatars are cached per theme. Concurrent calls for a value that is not
+// cached yet, e.g. a burst of requests for the avatar of a new user, render it once and share the
+// result, also across the Generators derived with With.
+func (g *Generator) Generate(value string) ([]byte, error) {
+	return g.GenerateContext(context.Background(), value)
+}
+
+// GenerateContext is like Generate, but follows the policy of WithDeadlinePolicy for avatars that
+// are not cached when ctx is close to its deadline. Downgraded avatars are cached separately.
+// It returns the error of ctx if ctx is done while waiting for the avatar, e.g. for the limit of
+// WithMaxParallelism, without failing the other calls waiting for the same avatar.
+func (g *Generator) GenerateContext(ctx context.Context, value string) ([]byte, error) {
+	opts, key := g.opts, g.style+"\x00"+value
+	if g.theme != nil {
+		theme := g.theme(time.Now())
+		opts = append(opts[:len(opts):len(opts)], withTheme(theme))
+		key = g.style + "\x00" + theme.Name + "\x00" + value
+	}
+	if data, ok := g.cache.get(key); ok {
+		return data, nil
+	}
+	if nearDeadline(ctx, g.margin) {
+		if g.policy == DEADLINE_DOWNGRADE {
+			opts, key = append(opts[:len(opts):len(opts)], withDowngrade()), key+downgradeSuffix
+			if data, ok := g.cache.get(key); ok {
+				return data, nil
+			}
+			// Downgraded avatars are rendered even past the deadline.
+			ctx = context.WithoutCancel(ctx)
+		} else if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	// The deadline policy is applied above, the render only waits on ctx and labels its profile.
+	opts = append(opts[:len(opts):len(opts)], WithDeadlinePolicy(DEADLINE_FAIL, 0))
+	return g.cache.do(ctx, key, func(ctx context.Context) ([]byte, error) {
+		return g.render(ctx, value, opts, key)
+	})
+}
+
+// render returns the avatar with the given cache key from the ByteCache, or renders and encodes it.
+func (g *Generator) render(ctx context.Context, value string, opts []CreateOption, key string) ([]byte, error) {
+	if g.remote != nil {
+		if data, ok, err := g.remote.Get(byteCacheKey(key)); err == nil && ok {
+			return data, nil
+		}
+	}
+	av := New(value, opts...)
+	av.canvases = g.canvases
+	result, err := av.GenerateContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	g.canvases.put(result.pixels)
+	data := result.Buffer.Bytes()
+	if g.remote != nil {
+		g.remote.Set(byteCacheKey(key), data, g.expiry)
+	}
+	return data, nil
+}
+
+// Preallocate keeps reusable canvases for avatars of the given dimensions, as set by WithDimension,
+// rendered with the scale factor of g. Steady-state servers rendering a few fixed sizes then reuse
+// the canvases instead of allocating a large image per avatar that the garbage collector has to free.
+func (g *Generator) Preallocate(sizes ...uint) {
+	for _, size := range sizes {
+		g.canvases.preallocate(New("", append(g.opts[:len(g.opts):len(g.opts)], WithDimension(size))...).outputDimension())
+	}
+}
+
+// Warm renders and caches the avatars for all values, so later calls to Generate are served from the cache.
+// Values that fail to render are skipped and their errors are returned together.
+func (g *Generator) Warm(values []string) error {
+	var errs []error
+	for _, value := range values {
+		if _, err := g.Generate(value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WarmRange warms the cache for the decimal representation of every ID from first to last, inclusive.
+func (g *Generator) WarmRange(first, last uint64) error {
+	var errs []error
+	for id := first; id <= last; id++ {
+		if _, err := g.Generate(strconv.FormatUint(id, 10)); err != nil {
+			errs = append(errs, err)
+		}
+		if id == last {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// memoryCache stores encoded avatars keyed by style and value, evicting the least recently used
+// avatars beyond its limits.
+type memoryCache struct {
+	mu sync.Mutex
+	// items holds the elements of order by key, order the cached avatars from most to least recently used.
+	items      map[string]*list.Element
+	order      *list.List
+	maxEntries int
+	maxBytes   int64
+	size       int64
+	// flights holds the avatars being rendered, by key.
+	flights map[string]*flight
+}
+
+// cacheEntry is an avatar in a memoryCache.
+type cacheEntry struct {
+	key  string
+	data []byte
+}
+
+// flight is an avatar being rendered, done is closed once data or err is set.
+type flight struct {
+	done chan struct{}
+	data []byte
+	err  error
+	// abandoned is set if render failed because the ctx of its caller is done.
+	abandoned bool
+}
+
+func newMemoryCache(maxEntries int, maxBytes int64) *memoryCache {
+	return &memoryCache{
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		flights:    make(map[string]*flight),
+	}
+}
+
+// do returns the cached data for key, or the result of render, which is stored on success.
+// Concurrent calls for the same key wait for a single call of render, each until its own ctx is done.
+// A render that fails because the ctx of its caller is done is not shared: the waiting calls render
+// the avatar again with their own ctx.
+func (c *memoryCache) do(ctx context.Context, key string, render func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	for {
+		c.mu.Lock()
+		if data, ok := c.lookup(key); ok {
+			c.mu.Unlock()
+			return data, nil
+		}
+		if f, ok := c.flights[key]; ok {
+			c.mu.Unlock()
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			if f.abandoned {
+				continue
+			}
+			return f.data, f.err
+		}
+		f := &flight{done: make(chan struct{})}
+		c.flights[key] = f
+		c.mu.Unlock()
+
+		c.run(ctx, key, f, render)
+		return f.data, f.err
+	}
+}
+
+// run completes the flight f of key with the result of render. A panicking render still completes
+// the flight, with ErrRenderPanic for the waiting calls, before the panic propagates.
+func (c *memoryCache) run(ctx context.Context, key string, f *flight, render func(ctx context.Context) ([]byte, error)) {
+	completed := false
+	defer func() {
+		if !completed {
+			f.data, f.err = nil, ErrRenderPanic
+		}
+		c.mu.Lock()
+		delete(c.flights, key)
+		if f.err == nil {
+			c.add(key, f.data)
+		}
+		c.mu.Unlock()
+		close(f.done)
+	}()
+	f.data, f.err = render(ctx)
+	f.abandoned = f.err != nil && ctx.Err() != nil
+	completed = true
+}
+
+func (c *memoryCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lookup(key)
+}
+
+// lookup returns the data cached for key and marks it as most recently used. c.mu must be held.
+func (c *memoryCache) lookup(key string) ([]byte, bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).data, true
+}
+
+// add caches data for key and evicts the least recently used avatars beyond the limits.
+// Avatars larger than the byte limit are not cached. c.mu must be held.
+func (c *memoryCache) add(key string, data []byte) {
+	if c.maxEntries == 0 || int64(len(data)) > c.maxBytes {
+		return
+	}
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, data: data})
+	c.size += int64(len(data))
+	for c.order.Len() > c.maxEntries || c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove drops the cached avatar of e. c.mu must be held.
+func (c *memoryCache) remove(e *list.Element) {
+	entry := c.order.Remove(e).(*cacheEntry)
+	delete(c.items, entry.key)
+	c.size -= int64(len(entry.data))
+}
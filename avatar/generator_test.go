@@ -0,0 +1,191 @@
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheEviction(t *testing.T) {
+	c := newMemoryCache(3, 10)
+	render := func(data string) func(context.Context) ([]byte, error) {
+		return func(context.Context) ([]byte, error) { return []byte(data), nil }
+	}
+	for i := 0; i < 3; i++ {
+		c.do(context.Background(), strconv.Itoa(i), render("ab"))
+	}
+	c.get("0")
+	c.do(context.Background(), "3", render("cd"))
+	for key, want := range map[string]bool{"0": true, "1": false, "2": true, "3": true} {
+		if _, ok := c.get(key); ok != want {
+			t.Errorf("key %s cached: got %t, want %t", key, ok, want)
+		}
+	}
+	c.do(context.Background(), "4", render("efghij"))
+	if c.size > 10 || c.order.Len() != len(c.items) {
+		t.Errorf("size %d with %d entries and %d keys", c.size, c.order.Len(), len(c.items))
+	}
+	c.do(context.Background(), "5", render("too large to cache"))
+	if _, ok := c.get("5"); ok {
+		t.Error("avatar larger than the byte limit was cached")
+	}
+}
+
+func TestGeneratorMemoryCacheLimit(t *testing.T) {
+	g := NewGenerator(WithMemoryCacheLimit(2, 1<<20))
+	for _, value := range []string{"a", "b", "c"} {
+		if _, err := g.Generate(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := g.cache.order.Len(); n != 2 {
+		t.Errorf("got %d cached avatars, want 2", n)
+	}
+	first, _ := g.Generate("a")
+	second, _ := g.Generate("a")
+	if !bytes.Equal(first, second) {
+		t.Error("regenerated avatar differs")
+	}
+
+	if _, err := NewGenerator(WithMemoryCacheLimit(-1, 0)).Generate("a"); !errors.Is(err, ErrInvalidCacheLimit) {
+		t.Errorf("got %v, want ErrInvalidCacheLimit", err)
+	}
+	off := NewGenerator(WithMemoryCacheLimit(0, 0))
+	if _, err := off.Generate("a"); err != nil {
+		t.Fatal(err)
+	}
+	if n := off.cache.order.Len(); n != 0 {
+		t.Errorf("got %d cached avatars with the cache turned off", n)
+	}
+}
+
+func TestGeneratorDensity(t *testing.T) {
+	base := NewGenerator(WithDPI(72))
+	if _, err := base.Generate("octocat"); err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range []*Generator{
+		base.With(WithDPI(300)),
+		base.With(WithDimension(64), WithScaleFactor(2)),
+		base.With(WithFormat(FORMAT_JPEG), WithDPI(300)),
+	} {
+		if g.Style() == base.Style() {
+			t.Errorf("style %s is shared with a different density", g.Style())
+		}
+		data, err := g.Generate("octocat")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := New("octocat", append(g.opts, WithOutputType(OUTPUT_BUFFER))...).Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, want.Buffer.Bytes()) {
+			t.Errorf("style %s: got the avatar cached for another density", g.Style())
+		}
+	}
+}
+
+func TestGeneratorExtension(t *testing.T) {
+	for _, tc := range []struct {
+		opts []CreateOption
+		want string
+	}{
+		{nil, ".png"},
+		{[]CreateOption{WithFormat(FORMAT_JPEG)}, ".jpg"},
+		{[]CreateOption{WithFormat(FORMAT_SVG)}, ".svg"},
+		{[]CreateOption{WithFormat(FORMAT_AVIF)}, ""},
+		{[]CreateOption{WithFormat(FORMAT_AVIF), WithFormatFallback(nil)}, ".png"},
+	} {
+		if got := NewGenerator(tc.opts...).Extension(); got != tc.want {
+			t.Errorf("got %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestMemoryCacheRenderPanic(t *testing.T) {
+	c := newMemoryCache(10, 1<<20)
+	started, release, panicked := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(panicked)
+		defer func() { recover() }()
+		c.do(context.Background(), "key", func(context.Context) ([]byte, error) {
+			close(started)
+			<-release
+			panic("broken encoder")
+		})
+	}()
+	<-started
+	c.mu.Lock()
+	f := c.flights["key"]
+	c.mu.Unlock()
+	close(release)
+	<-panicked
+	select {
+	case <-f.done:
+	default:
+		t.Fatal("flight of the panicked render is not done")
+	}
+	if !errors.Is(f.err, ErrRenderPanic) {
+		t.Errorf("waiting calls get %v, want ErrRenderPanic", f.err)
+	}
+	data, err := c.do(context.Background(), "key", func(context.Context) ([]byte, error) { return []byte("ok"), nil })
+	if err != nil || string(data) != "ok" {
+		t.Errorf("after the panic: got %q, %v", data, err)
+	}
+}
+
+func TestGeneratorContextCancelsWait(t *testing.T) {
+	limit := WithMaxParallelism(1)
+	limiter := New("", limit).limiter
+	limiter <- struct{}{}
+	defer func() { <-limiter }()
+	g := NewGenerator(limit)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := g.GenerateContext(ctx, "octocat")
+		done <- err
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("GenerateContext kept waiting for the parallelism limit after ctx was canceled")
+	}
+}
+
+func TestMemoryCacheAbandonedFlight(t *testing.T) {
+	c := newMemoryCache(10, 1<<20)
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	leader := make(chan error)
+	go func() {
+		_, err := c.do(ctx, "key", func(ctx context.Context) ([]byte, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		leader <- err
+	}()
+	<-started
+	waiter := make(chan []byte)
+	go func() {
+		data, _ := c.do(context.Background(), "key", func(context.Context) ([]byte, error) { return []byte("ok"), nil })
+		waiter <- data
+	}()
+	cancel()
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled call: got %v, want context.Canceled", err)
+	}
+	if data := <-waiter; string(data) != "ok" {
+		t.Errorf("waiting call: got %q, want its own render", data)
+	}
+}
@@ -0,0 +1,72 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+)
+
+// WithGradientBackground replaces the flat background with a two-stop gradient derived from the value.
+// The pattern is drawn in white or black on top, whichever contrasts better with the gradient.
+// In dark mode the gradient uses darker shades.
+func WithGradientBackground() func(a *Avatar) {
+	return func(a *Avatar) {
+		a.gradientBackground = true
+	}
+}
+
+// gradientStops derives the two colors of the background gradient from the value hash.
+func (av *Avatar) gradientStops(hash [sha256.Size]byte) (from, to color.RGBA) {
+	lightness := 0.55
+	if av.darkMode {
+		lightness = 0.35
+	}
+	hash = av.entropySplit.colorHash(hash)
+	hue := hashFraction(hash[0:4]) * 360
+	shift := 30 + hashFraction(hash[4:8])*60
+	return fromHSL(hue, 0.65, lightness), fromHSL(hue+shift, 0.65, lightness)
+}
+
+// gradientForeground returns the pattern color used on top of the background gradient.
+func (av *Avatar) gradientForeground(hash [sha256.Size]byte) color.Color {
+	from, to := av.gradientStops(hash)
+	if (luminance(from)+luminance(to))/2 > contrastLuminance {
+		return color.Black
+	}
+	return color.White
+}
+
+// drawGradientBackground composes the rendered pattern over the background gradient.
+// The gradient runs diagonally from the top left to the bottom right corner.
+func (av *Avatar) drawGradientBackground(hash [sha256.Size]byte) {
+	from, to := av.gradientStops(hash)
+	bounds := av.image.Bounds()
+	canvas := image.NewRGBA(bounds)
+	fillGradient(canvas, from, to)
+	draw.Draw(canvas, bounds, av.image, bounds.Min, draw.Over)
+	av.image = canvas
+}
+
+// fillGradient fills img with a gradient running diagonally from the top left to the bottom right corner.
+func fillGradient(img *image.RGBA, from, to color.RGBA) {
+	bounds := img.Bounds()
+	span := float64(bounds.Dx() + bounds.Dy() - 2)
+	if span <= 0 {
+		span = 1
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			img.SetRGBA(x, y, lerpRGBA(from, to, float64(x-bounds.Min.X+y-bounds.Min.Y)/span))
+		}
+	}
+}
+
+// lerpRGBA interpolates linearly between two colors, t ranges from 0 to 1.
+func lerpRGBA(from, to color.RGBA, t float64) color.RGBA {
+	lerp := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*t + 0.5)
+	}
+	return color.RGBA{lerp(from.R, to.R), lerp(from.G, to.G), lerp(from.B, to.B), lerp(from.A, to.A)}
+}
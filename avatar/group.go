@@ -0,0 +1,137 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// maxGroupMembers is the number of members shown in a group avatar.
+const maxGroupMembers = 4
+
+// GenerateGroup creates a deterministic avatar for a group of values, such as a team or a channel.
+// Values are sanitized with WithValueSanitization, then sorted and deduplicated, so the result does not
+// depend on their order, and every member must pass the policy of WithEmptyValuePolicy.
+// Two members are shown as a diagonal split, three or four members as a 2x2 grid, where the
+// free quadrant of a three member group is filled with a blend of the members' colors.
+// Only the first four members are shown. The options apply to the group avatar as a whole.
+func GenerateGroup(values []string, opts ...CreateOption) (*AvatarResult, error) {
+	group := New("", opts...)
+	members := groupMembers(values, group.sanitizeValue)
+	if len(members) == 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOption, ErrNoValues)
+	}
+	group.value = strings.Join(members, "\x00")
+	if err := group.validate(); err != nil {
+		return nil, err
+	}
+	size := group.outputDimension()
+	half := size / 2
+	group.image = image.NewRGBA(image.Rect(0, 0, size, size))
+
+	dimension := size
+	if len(members) > 2 {
+		dimension = size - half
+	}
+	rendered := make([]*Avatar, len(members))
+	for i, member := range members {
+		av, err := group.renderMember(member, dimension)
+		if err != nil {
+			return nil, err
+		}
+		rendered[i] = av
+	}
+
+	switch len(members) {
+	case 1:
+		draw.Draw(group.image, group.image.Bounds(), rendered[0].image, image.Point{}, draw.Src)
+	case 2:
+		first, second := rendered[0].image, rendered[1].image
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				if x+y < size {
+					group.image.Set(x, y, first.At(x, y))
+				} else {
+					group.image.Set(x, y, second.At(x, y))
+				}
+			}
+		}
+	default:
+		quadrants := []image.Rectangle{
+			image.Rect(0, 0, half, half),
+			image.Rect(half, 0, size, half),
+			image.Rect(0, half, half, size),
+			image.Rect(half, half, size, size),
+		}
+		colors := make([]color.Color, 0, len(members))
+		for i, av := range rendered {
+			draw.Draw(group.image, quadrants[i], av.image, image.Point{}, draw.Src)
+			colors = append(colors, av.color(sha256.Sum256([]byte(av.value))))
+		}
+		if len(members) == 3 {
+			draw.Draw(group.image, quadrants[3], image.NewUniform(blendColors(colors)), image.Point{}, draw.Src)
+		}
+	}
+
+	return group.output()
+}
+
+// renderMember renders the avatar of a single group member with the group's style at the given dimension.
+// The member value is sanitized and checked against the empty value policy like the value of an avatar.
+func (av *Avatar) renderMember(value string, dimension int) (*Avatar, error) {
+	member := *av
+	member.value = value
+	if av.sanitizeValue {
+		member.value = SanitizeValue(value)
+	}
+	member.applyOverride()
+	if !member.overridden {
+		if err := member.checkValue(); err != nil {
+			return nil, member.wrapErr(ErrInvalidOption, "check member value", err)
+		}
+	}
+	member.dimension = uint(dimension)
+	member.scaleFactor = 1
+	member.snapToGrid = false
+	if err := member.render(); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// groupMembers returns the sorted, deduplicated values shown in a group avatar, sanitized first if sanitize is set.
+func groupMembers(values []string, sanitize bool) []string {
+	members := append([]string(nil), values...)
+	if sanitize {
+		for i, member := range members {
+			members[i] = SanitizeValue(member)
+		}
+	}
+	sort.Strings(members)
+	unique := members[:0]
+	for i, member := range members {
+		if i == 0 || member != members[i-1] {
+			unique = append(unique, member)
+		}
+	}
+	if len(unique) > maxGroupMembers {
+		unique = unique[:maxGroupMembers]
+	}
+	return unique
+}
+
+// blendColors returns the average of the given colors.
+func blendColors(colors []color.Color) color.Color {
+	var r, g, b, a uint32
+	for _, c := range colors {
+		cr, cg, cb, ca := c.RGBA()
+		r, g, b, a = r+cr, g+cg, b+cb, a+ca
+	}
+	n := uint32(len(colors))
+	return color.RGBA64{uint16(r / n), uint16(g / n), uint16(b / n), uint16(a / n)}
+}
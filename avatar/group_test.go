@@ -0,0 +1,32 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestGenerateGroupMembers(t *testing.T) {
+	generate := func(values []string, opts ...CreateOption) []byte {
+		t.Helper()
+		result, err := GenerateGroup(values, append(opts, WithOutputType(OUTPUT_BUFFER))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Buffer.Bytes()
+	}
+	if !bytes.Equal(generate([]string{"b", "a"}), generate([]string{"a", "b", "a"})) {
+		t.Error("group depends on the order or duplicates of its members")
+	}
+	// The fullwidth and the zero-width forms sanitize to "jane".
+	sanitized := generate([]string{"jane", "\uff4a\uff41\uff4e\uff45", "ja\u200bne"}, WithValueSanitization())
+	if !bytes.Equal(sanitized, generate([]string{"jane"}, WithValueSanitization())) {
+		t.Error("members sanitizing to the same value are shown twice")
+	}
+
+	_, err := GenerateGroup([]string{"jane", " "}, WithOutputType(OUTPUT_BUFFER))
+	if !errors.Is(err, ErrEmptyValue) || !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("got %v, want ErrInvalidOption wrapping ErrEmptyValue", err)
+	}
+	generate([]string{"jane", " "}, WithEmptyValuePolicy(EMPTY_VALUE_PLACEHOLDER))
+}
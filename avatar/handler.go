@@ -0,0 +1,161 @@
+package avatar
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// negotiationOrder lists the formats in the order NegotiateFormat prefers them, smallest responses first.
+var negotiationOrder = []Format{FORMAT_SVG, FORMAT_AVIF, FORMAT_WEBP, FORMAT_PNG, FORMAT_GIF, FORMAT_JPEG}
+
+// NegotiateFormat returns the format to send a client with the given Accept header. Among the formats
+// with a registered encoder it picks the one with the highest quality value, preferring SVG, AVIF and
+// WebP over PNG on ties because they are smaller. Formats other than PNG must be listed explicitly, so
+// wildcards like "*/*" and missing or unsatisfiable headers get FORMAT_PNG.
+func NegotiateFormat(acceptHeader string) Format {
+	best, bestQuality := FORMAT_PNG, 0.0
+	for _, format := range negotiationOrder {
+		if _, ok := lookupEncoder(format); !ok {
+			continue
+		}
+		if q := acceptQuality(acceptHeader, format.MIMEType(), format == FORMAT_PNG); q > bestQuality {
+			best, bestQuality = format, q
+		}
+	}
+	return best
+}
+
+// acceptQuality returns the quality value the Accept header gives the media type, 0 if it is not
+// acceptable. The most specific media range wins, wildcards only count if wildcard is set.
+func acceptQuality(header, mimeType string, wildcard bool) float64 {
+	quality, specificity := 0.0, -1
+	for _, part := range strings.Split(header, ",") {
+		mediaRange, params, _ := strings.Cut(part, ";")
+		mediaRange = strings.ToLower(strings.TrimSpace(mediaRange))
+		var s int
+		switch {
+		case mediaRange == mimeType:
+			s = 2
+		case wildcard && mediaRange == mimeType[:strings.Index(mimeType, "/")+1]+"*":
+			s = 1
+		case wildcard && mediaRange == "*/*":
+			s = 0
+		default:
+			continue
+		}
+		if s <= specificity {
+			continue
+		}
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				if v, err := strconv.ParseFloat(value, 64); err == nil {
+					q = v
+				}
+			}
+		}
+		quality, specificity = q, s
+	}
+	return max(quality, 0)
+}
+
+// maxHandlerSize is the largest dimension, in pixels, clients may request from Handler.
+const maxHandlerSize = 1024
+
+// handler serves avatars in negotiated formats.
+type handler struct {
+	base *Generator
+	// pattern is the pixel pattern of the options, the smallest size clients may request.
+	pattern    int
+	mu         sync.Mutex
+	generators map[handlerVariant]*Generator
+}
+
+// handlerVariant is the format and the query parameters of a request to Handler.
+type handlerVariant struct {
+	format Format
+	size   int
+	dark   bool
+}
+
+// NewHandler returns an HTTP handler serving the avatar for the request path without its leading slash,
+// e.g. with http.StripPrefix("/avatars/", avatar.NewHandler()) for "/avatars/jane@example.com". The
+// format is negotiated from the Accept header with NegotiateFormat, so browsers that accept SVG or
+// WebP get smaller responses. The options apply to every avatar, see NewGenerator; avatars are cached
+// within the limits of WithMemoryCacheLimit. The query parameters size, a dimension from the pixel
+// pattern to 1024 pixels, and dark, a boolean like "1", override WithDimension and enable
+// WithDarkMode, e.g. "/avatars/jane@example.com?size=200&dark=1". Invalid requests get 400 Bad Request.
+func NewHandler(opts ...CreateOption) http.Handler {
+	return &handler{
+		base:       NewGenerator(opts...),
+		pattern:    int(New("", opts...).pixelPattern),
+		generators: make(map[handlerVariant]*Generator),
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	value := strings.TrimPrefix(r.URL.Path, "/")
+	if value == "" {
+		http.NotFound(w, r)
+		return
+	}
+	variant := handlerVariant{format: NegotiateFormat(r.Header.Get("Accept"))}
+	query := r.URL.Query()
+	if size := query.Get("size"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < max(h.pattern, 1) || n > maxHandlerSize {
+			http.Error(w, fmt.Sprintf("invalid size, expected %d to %d", max(h.pattern, 1), maxHandlerSize), http.StatusBadRequest)
+			return
+		}
+		variant.size = n
+	}
+	if dark := query.Get("dark"); dark != "" {
+		var err error
+		if variant.dark, err = strconv.ParseBool(dark); err != nil {
+			http.Error(w, "invalid dark, expected a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+	data, err := h.generator(variant).GenerateContext(r.Context(), value)
+	switch {
+	case errors.Is(err, ErrEmptyValue):
+		http.NotFound(w, r)
+		return
+	case errors.Is(err, ErrInvalidOption):
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	case err != nil:
+		// Errors name internals such as file paths, so clients only get the status.
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", variant.format.MIMEType())
+	w.Header().Set("Vary", "Accept")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
+}
+
+// generator returns the Generator for the variant, sharing the cache of the base Generator.
+func (h *handler) generator(variant handlerVariant) *Generator {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	g, ok := h.generators[variant]
+	if !ok {
+		opts := []CreateOption{WithFormat(variant.format)}
+		if variant.size > 0 {
+			opts = append(opts, WithDimension(uint(variant.size)))
+		}
+		if variant.dark {
+			opts = append(opts, WithDarkMode())
+		}
+		g = h.base.With(opts...)
+		h.generators[variant] = g
+	}
+	return g
+}
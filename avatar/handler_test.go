@@ -0,0 +1,49 @@
+package avatar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	h := NewHandler(WithPixelPattern(PIXEL_PATTERN_7))
+	for _, tc := range []struct {
+		target string
+		status int
+	}{
+		{"/jane@example.com", http.StatusOK},
+		{"/jane@example.com?size=7&dark=1", http.StatusOK},
+		{"/jane@example.com?size=1024", http.StatusOK},
+		{"/jane@example.com?size=3", http.StatusBadRequest},
+		{"/jane@example.com?size=0", http.StatusBadRequest},
+		{"/jane@example.com?size=1025", http.StatusBadRequest},
+		{"/jane@example.com?size=big", http.StatusBadRequest},
+		{"/jane@example.com?dark=maybe", http.StatusBadRequest},
+		{"/%20", http.StatusNotFound},
+		{"/", http.StatusNotFound},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.target, nil))
+		if rec.Code != tc.status {
+			t.Errorf("%s: got status %d, want %d: %s", tc.target, rec.Code, tc.status, rec.Body)
+		}
+		if tc.status == http.StatusOK && rec.Header().Get("Content-Type") != "image/png" {
+			t.Errorf("%s: got content type %q", tc.target, rec.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestHandlerHidesErrors(t *testing.T) {
+	// Half of 5px is smaller than the pattern, which is only detected when rendering.
+	h := NewHandler(WithScaleFactor(0.5))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jane@example.com?size=5", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "value") || strings.Contains(body, "dimension") {
+		t.Errorf("response leaks the error: %s", body)
+	}
+}
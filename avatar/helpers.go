@@ -1,7 +1,10 @@
 package avatar
 
 import (
+	"fmt"
+	"image/color"
 	"os"
+	"strings"
 )
 
 func byteSum(data []byte) uint8 {
@@ -12,12 +15,29 @@ func byteSum(data []byte) uint8 {
 	return sum
 }
 
+// ensurePath creates the directory at path unless it exists.
 func ensurePath(path string) error {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		createErr := os.MkdirAll(path, 0755)
-		if createErr != nil {
-			return err
+	return os.MkdirAll(path, 0755)
+}
+
+// paletteKey returns a string identifying the colors of a palette.
+func paletteKey(palette []color.Color) string {
+	var key strings.Builder
+	for _, c := range palette {
+		if c == nil {
+			continue
 		}
+		r, g, b, a := c.RGBA()
+		fmt.Fprintf(&key, "-%04x%04x%04x%04x", r, g, b, a)
+	}
+	return key.String()
+}
+
+// pathKey returns a string identifying the points of a mask path.
+func pathKey(path []Point) string {
+	var key strings.Builder
+	for _, p := range path {
+		fmt.Fprintf(&key, "-%g,%g", p.X, p.Y)
 	}
-	return nil
+	return key.String()
 }
@@ -0,0 +1,59 @@
+package avatar
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Maximum lengths of DNS names without the trailing dot and of their labels.
+const (
+	maxHostNameLength  = 253
+	maxHostLabelLength = 63
+)
+
+// NormalizeHost returns the canonical form of an IP address, CIDR prefix or host name, so different
+// spellings of a host get the same avatar: host names are lower cased without the trailing dot of
+// fully qualified names, IPv6 addresses take their RFC 5952 form without brackets, IPv4-mapped IPv6
+// addresses become IPv4 addresses and prefixes drop the host bits, e.g. "10.1.2.3/8" becomes
+// "10.0.0.0/8". Internationalized names must consistently be given either as Unicode or as punycode.
+func NormalizeHost(host string) (string, error) {
+	host = strings.TrimSpace(host)
+	if strings.Contains(host, "/") {
+		prefix, err := netip.ParsePrefix(host)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrInvalidHost, err)
+		}
+		if addr := prefix.Addr(); addr.Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(addr.Unmap(), prefix.Bits()-96)
+		}
+		return prefix.Masked().String(), nil
+	}
+	if addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")); err == nil {
+		return addr.Unmap().String(), nil
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(host, "."))
+	if name == "" || len(name) > maxHostNameLength {
+		return "", fmt.Errorf("%w: %q", ErrInvalidHost, host)
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > maxHostLabelLength || strings.ContainsAny(label, " \t/\\@:[]") {
+			return "", fmt.Errorf("%w: %q", ErrInvalidHost, host)
+		}
+	}
+	return name, nil
+}
+
+// NewHost creates an Avatar for the normalized form of an IP address, CIDR prefix or host name,
+// see NormalizeHost, so network dashboards get one stable avatar per host. Generate returns an error
+// wrapping ErrInvalidHost if host is none of them.
+func NewHost(host string, opts ...CreateOption) *Avatar {
+	value, err := NormalizeHost(host)
+	if err != nil {
+		av := New(host, opts...)
+		av.err = err
+		return av
+	}
+	return New(value, opts...)
+}
@@ -0,0 +1,88 @@
+package avatar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// CheckSymmetry checks that img is mirror symmetric about its vertical (left to right) or
+// horizontal (top to bottom) center axis. The returned error wraps ErrNotSymmetric and names
+// the first mismatching pixel pair.
+func CheckSymmetry(img image.Image, axis Axis) error {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			mx, my := b.Max.X-1-(x-b.Min.X), y
+			if axis == AXIS_HORIZONTAL {
+				mx, my = x, b.Max.Y-1-(y-b.Min.Y)
+			}
+			if !sameColor(img.At(x, y), img.At(mx, my)) {
+				return fmt.Errorf("%w: pixel (%d,%d) differs from (%d,%d)", ErrNotSymmetric, x, y, mx, my)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckPaletteMembership checks that every pixel of img has one of the colors of palette.
+// The returned error wraps ErrColorNotInPalette and names the first offending pixel.
+func CheckPaletteMembership(img image.Image, palette []color.Color) error {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.At(x, y)
+			found := false
+			for _, p := range palette {
+				if sameColor(c, p) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("%w: pixel (%d,%d) has color %v", ErrColorNotInPalette, x, y, c)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateAlgorithm runs the algorithm registered as algo for each of values at every supported pixel pattern
+// and checks that it does not panic, sets every pixel to the fill or background color and draws
+// the same pattern when run twice. Options such as WithAlgorithmParams apply to every run.
+// It is meant for tests of custom algorithms before they are used in production.
+func ValidateAlgorithm(algo Algorithm, values []string, opts ...CreateOption) error {
+	for _, value := range values {
+		for _, pattern := range pixelPatterns {
+			av, err := New(value, append(opts[:len(opts):len(opts)], WithAlgorithm(algo), WithPixelPattern(pattern))...).prepare()
+			if err != nil {
+				return err
+			}
+			first, err := av.patternImage()
+			if err != nil {
+				return err
+			}
+			fill, background := av.patternColors(sha256.Sum256([]byte(value)))
+			if err := CheckPaletteMembership(first, []color.Color{fill, background}); err != nil {
+				return av.wrapErr(ErrInvalidOption, fmt.Sprintf("validate algorithm %d at pattern %d", algo, pattern), err)
+			}
+			second, err := av.patternImage()
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(first.Pix, second.Pix) {
+				return av.wrapErr(ErrInvalidOption, fmt.Sprintf("validate algorithm %d at pattern %d", algo, pattern), ErrNotDeterministic)
+			}
+		}
+	}
+	return nil
+}
+
+// sameColor reports whether two colors have the same premultiplied RGBA values.
+func sameColor(a, b color.Color) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return ar == br && ag == bg && ab == bb && aa == ba
+}
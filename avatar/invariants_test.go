@@ -0,0 +1,124 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"errors"
+	"image"
+	"image/color"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+var invariantValues = []string{"abhinavsingh", "octocat", "user@example.com", "42", "ünïcödé"}
+
+// registeredAlgorithms returns the algorithms registered at the time of the call in ascending order.
+func registeredAlgorithms() []Algorithm {
+	algoMu.RLock()
+	defer algoMu.RUnlock()
+	algos := make([]Algorithm, 0, len(algoExecutorMap))
+	for algo := range algoExecutorMap {
+		algos = append(algos, algo)
+	}
+	sort.Slice(algos, func(i, j int) bool { return algos[i] < algos[j] })
+	return algos
+}
+
+func TestValidateAlgorithm(t *testing.T) {
+	for _, algo := range registeredAlgorithms() {
+		if err := ValidateAlgorithm(algo, invariantValues); err != nil {
+			t.Errorf("algorithm %d: %v", algo, err)
+		}
+		if err := ValidateAlgorithm(algo, invariantValues, WithPRNG(PRNG_CHACHA8), WithDarkMode()); err != nil {
+			t.Errorf("algorithm %d with options: %v", algo, err)
+		}
+	}
+}
+
+func TestPatternInvariants(t *testing.T) {
+	for _, algo := range registeredAlgorithms() {
+		for _, pattern := range pixelPatterns {
+			for _, value := range invariantValues {
+				av := New(value, WithAlgorithm(algo), WithPixelPattern(pattern))
+				img, err := av.PatternImage()
+				if err != nil {
+					t.Fatalf("algorithm %d, pattern %d, %q: %v", algo, pattern, value, err)
+				}
+				if got := img.Bounds(); got != image.Rect(0, 0, int(pattern), int(pattern)) {
+					t.Errorf("algorithm %d, pattern %d, %q: bounds %v", algo, pattern, value, got)
+				}
+				fill, background := av.patternColors(sha256.Sum256([]byte(value)))
+				if err := CheckPaletteMembership(img, []color.Color{fill, background}); err != nil {
+					t.Errorf("algorithm %d, pattern %d, %q: %v", algo, pattern, value, err)
+				}
+				// ALGORITHM_1 and ALGORITHM_2 draw the middle column pair of even patterns independently,
+				// changing that would change every existing PIXEL_PATTERN_12 avatar.
+				if (algo == ALGORITHM_1 || algo == ALGORITHM_2) && pattern%2 == 1 {
+					if err := CheckSymmetry(img, AXIS_VERTICAL); err != nil {
+						t.Errorf("algorithm %d, pattern %d, %q: %v", algo, pattern, value, err)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCheckSymmetry(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(0, 0, color.Black)
+	img.Set(2, 0, color.Black)
+	if err := CheckSymmetry(img, AXIS_VERTICAL); err != nil {
+		t.Errorf("vertical: %v", err)
+	}
+	if err := CheckSymmetry(img, AXIS_HORIZONTAL); !errors.Is(err, ErrNotSymmetric) {
+		t.Errorf("horizontal: got %v, want ErrNotSymmetric", err)
+	}
+}
+
+func TestCheckPaletteMembership(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(1, 1, color.White)
+	if err := CheckPaletteMembership(img, []color.Color{color.Transparent, color.White}); err != nil {
+		t.Errorf("in palette: %v", err)
+	}
+	if err := CheckPaletteMembership(img, []color.Color{color.White}); !errors.Is(err, ErrColorNotInPalette) {
+		t.Errorf("not in palette: got %v, want ErrColorNotInPalette", err)
+	}
+}
+
+func TestValidateAlgorithmRejects(t *testing.T) {
+	const (
+		offPalette Algorithm = 1000 + iota
+		random
+		panicking
+	)
+	RegisterAlgorithm(offPalette, func(img *image.RGBA, size int, r *rand.Rand, fill, background color.Color, params any) {
+		algorithm_three(img, size, r, fill, background, params)
+		img.Set(0, 0, color.RGBA{1, 2, 3, 255})
+	}, nil)
+	seed := int64(0)
+	RegisterAlgorithm(random, func(img *image.RGBA, size int, _ *rand.Rand, fill, background color.Color, params any) {
+		seed++
+		algorithm_three(img, size, rand.New(rand.NewSource(seed)), fill, background, params)
+	}, nil)
+	RegisterAlgorithm(panicking, func(*image.RGBA, int, *rand.Rand, color.Color, color.Color, any) {
+		panic("broken")
+	}, nil)
+	t.Cleanup(func() {
+		algoMu.Lock()
+		defer algoMu.Unlock()
+		delete(algoExecutorMap, offPalette)
+		delete(algoExecutorMap, random)
+		delete(algoExecutorMap, panicking)
+	})
+
+	for algo, want := range map[Algorithm]error{
+		offPalette: ErrColorNotInPalette,
+		random:     ErrNotDeterministic,
+		panicking:  ErrAlgorithmPanic,
+	} {
+		if err := ValidateAlgorithm(algo, invariantValues); !errors.Is(err, want) {
+			t.Errorf("algorithm %d: got %v, want %v", algo, err, want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package avatar
+
+import (
+	"encoding/hex"
+	"image"
+	"image/color"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// Dimensions of the key art board in cells.
+const (
+	keyArtWidth  = 17
+	keyArtHeight = 9
+)
+
+// keyArtSymbols are the characters of cells visited 0 to 14 times, followed by the start and end marks.
+const keyArtSymbols = " .o+=*BOX@%&#/^SE"
+
+// KeyArt returns the "drunken bishop" random art of a key fingerprint, the 17x9 board that OpenSSH
+// prints for host keys and that OpenPGP tools adopted for key fingerprints, so Go key servers and
+// clients show the same art as other toolchains. Pass the raw fingerprint bytes, not their hex form.
+// The title and footer, e.g. "RSA 4096" and "SHA256", are centered in brackets on the borders and
+// left out if empty. The result has no trailing newline.
+func KeyArt(fingerprint []byte, title, footer string) string {
+	var field [keyArtWidth][keyArtHeight]int
+	x, y := keyArtWidth/2, keyArtHeight/2
+	const maxVisits = len(keyArtSymbols) - 3
+	for _, b := range fingerprint {
+		// Every byte moves the bishop four times, two bits at a time from the least significant ones.
+		for i := 0; i < 4; i++ {
+			x = min(max(x+int(b&1)*2-1, 0), keyArtWidth-1)
+			y = min(max(y+int(b&2)-1, 0), keyArtHeight-1)
+			if field[x][y] < maxVisits {
+				field[x][y]++
+			}
+			b >>= 2
+		}
+	}
+	field[keyArtWidth/2][keyArtHeight/2] = len(keyArtSymbols) - 2
+	field[x][y] = len(keyArtSymbols) - 1
+
+	var b strings.Builder
+	b.WriteString(keyArtBorder(title))
+	b.WriteByte('\n')
+	for row := 0; row < keyArtHeight; row++ {
+		b.WriteByte('|')
+		for col := 0; col < keyArtWidth; col++ {
+			b.WriteByte(keyArtSymbols[field[col][row]])
+		}
+		b.WriteString("|\n")
+	}
+	b.WriteString(keyArtBorder(footer))
+	return b.String()
+}
+
+// keyArtBorder returns a border line of the key art with label centered in brackets like OpenSSH.
+func keyArtBorder(label string) string {
+	if label != "" {
+		label = "[" + label + "]"
+	}
+	if len(label) >= keyArtWidth {
+		label = label[:keyArtWidth-2] + "]"
+	}
+	left := (keyArtWidth - len(label)) / 2
+	return "+" + strings.Repeat("-", left) + label + strings.Repeat("-", keyArtWidth-left-len(label)) + "+"
+}
+
+// GenerateKeyArt renders the KeyArt of a fingerprint as an image with the built-in bitmap font,
+// as wide as the output dimension and black on white or, in dark mode, white on black.
+func GenerateKeyArt(fingerprint []byte, title, footer string, opts ...CreateOption) (*AvatarResult, error) {
+	art := New(hex.EncodeToString(fingerprint), opts...)
+	if err := art.validate(); err != nil {
+		return nil, err
+	}
+	lines := strings.Split(KeyArt(fingerprint, title, footer), "\n")
+
+	background, foreground := getBackgroundColor(art.darkMode), color.Color(color.Black)
+	if art.darkMode {
+		foreground = color.White
+	}
+	width := art.outputDimension()
+	padding := width / 16
+	scale := fitTextScale(lines[0], width-2*padding, width)
+	lineHeight := textHeight(scale) + scale
+	art.image = image.NewRGBA(image.Rect(0, 0, width, 2*padding+len(lines)*lineHeight-scale))
+	draw.Draw(art.image, art.image.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	x := (width - textWidth(lines[0], scale)) / 2
+	for i, line := range lines {
+		drawText(art.image, line, x, padding+i*lineHeight, scale, foreground)
+	}
+	return art.output()
+}
@@ -0,0 +1,81 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+)
+
+// PatternMatrix returns the cells of the avatar's pixel pattern row by row, true for filled cells.
+func (av *Avatar) PatternMatrix() ([][]bool, error) {
+	av, err := av.prepare()
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := av.patternImage()
+	if err != nil {
+		return nil, err
+	}
+	fill, _ := av.patternColors(sha256.Sum256([]byte(av.value)))
+	size := int(av.pixelPattern)
+	matrix := make([][]bool, size)
+	for y := range matrix {
+		matrix[y] = make([]bool, size)
+		for x := range matrix[y] {
+			matrix[y][x] = sameColor(pattern.At(x, y), fill)
+		}
+	}
+	return matrix, nil
+}
+
+// LEDRows returns the pattern centered on an LED matrix of width by height LEDs, at most 64 wide,
+// as one bit mask per row with the most significant of the width bits being the leftmost LED,
+// e.g. to show a device identity on an 8x8 grid. Patterns larger than the grid return an error
+// wrapping ErrPatternTooLarge.
+func (av *Avatar) LEDRows(width, height int) ([]uint64, error) {
+	size := int(av.pixelPattern)
+	if size > width || size > height || width > 64 {
+		return nil, av.wrapErr(ErrInvalidOption, "map pattern to LEDs",
+			fmt.Errorf("%w: %dx%d pattern on %dx%d grid", ErrPatternTooLarge, size, size, width, height))
+	}
+	matrix, err := av.PatternMatrix()
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]uint64, height)
+	top, left := (height-size)/2, (width-size)/2
+	for y, cells := range matrix {
+		for x, filled := range cells {
+			if filled {
+				rows[top+y] |= 1 << (width - 1 - left - x)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// HapticSequence returns the pattern read row by row as alternating on and off durations, starting
+// with on, where every cell lasts unit, e.g. for vibration motors or a single blinking LED.
+// The first duration is 0 if the pattern starts with an empty cell. Returns an error wrapping
+// ErrInvalidOption if unit is not positive.
+func (av *Avatar) HapticSequence(unit time.Duration) ([]time.Duration, error) {
+	if unit <= 0 {
+		return nil, av.wrapErr(ErrInvalidOption, "check haptic unit", fmt.Errorf("%w: %v", ErrInvalidHapticUnit, unit))
+	}
+	matrix, err := av.PatternMatrix()
+	if err != nil {
+		return nil, err
+	}
+	sequence := []time.Duration{0}
+	on := true
+	for _, cells := range matrix {
+		for _, filled := range cells {
+			if filled != on {
+				sequence = append(sequence, 0)
+				on = filled
+			}
+			sequence[len(sequence)-1] += unit
+		}
+	}
+	return sequence, nil
+}
@@ -0,0 +1,35 @@
+package avatar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHapticSequence(t *testing.T) {
+	av := New("octocat")
+	for _, unit := range []time.Duration{0, -time.Millisecond} {
+		if _, err := av.HapticSequence(unit); !errors.Is(err, ErrInvalidOption) || !errors.Is(err, ErrInvalidHapticUnit) {
+			t.Errorf("unit %v: got %v, want ErrInvalidOption wrapping ErrInvalidHapticUnit", unit, err)
+		}
+	}
+
+	matrix, err := av.PatternMatrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sequence, err := av.HapticSequence(time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total time.Duration
+	for i, d := range sequence {
+		if d <= 0 && i > 0 {
+			t.Errorf("duration %d is %v", i, d)
+		}
+		total += d
+	}
+	if want := time.Duration(len(matrix)*len(matrix)) * time.Millisecond; total != want {
+		t.Errorf("sequence lasts %v, want %v", total, want)
+	}
+}
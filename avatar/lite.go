@@ -0,0 +1,149 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// maxLitePattern is the largest pixel pattern GeneratorLite supports, the bits of a row mask.
+const maxLitePattern = 64
+
+// GeneratorLite computes the pixel patterns of avatars without rendering images, for hot paths such
+// as pattern descriptors that must not allocate. It supports the built-in algorithms only and is safe
+// for concurrent use.
+type GeneratorLite struct {
+	size  int
+	algo  Algorithm
+	prng  PRNG
+	bias  float64
+	split *entropySplit
+}
+
+// NewGeneratorLite creates a GeneratorLite for the pixel pattern, algorithm, algorithm parameters,
+// PRNG and entropy split set by opts. Other options do not change the pattern and are ignored.
+// Pixel patterns larger than 64 cells, the bits of a row mask, return an error wrapping ErrPatternTooLarge.
+func NewGeneratorLite(opts ...CreateOption) (*GeneratorLite, error) {
+	av := New("", opts...)
+	if err := av.validateOptions(); err != nil {
+		return nil, err
+	}
+	if av.pixelPattern > maxLitePattern {
+		return nil, av.wrapErr(ErrInvalidOption, "check pixel pattern",
+			fmt.Errorf("%w: %dx%d pattern does not fit into %d bit rows", ErrPatternTooLarge, av.pixelPattern, av.pixelPattern, maxLitePattern))
+	}
+	if algo, _ := lookupAlgorithm(av.algo); !algo.builtin {
+		return nil, av.wrapErr(ErrInvalidOption, "check algorithm",
+			fmt.Errorf("%w: custom algorithm %d is not supported without rendering", ErrUnknownAlgorithm, av.algo))
+	}
+	return &GeneratorLite{
+		size:  int(av.pixelPattern),
+		algo:  av.algo,
+		prng:  av.prng,
+		bias:  fillBias(av.algoParams),
+		split: av.entropySplit,
+	}, nil
+}
+
+// PatternInto writes the pattern for value into dst as one bit mask per row, top row first, in which
+// bit x is set if the cell in column x is filled. dst must hold a row per cell of the pixel pattern,
+// otherwise io.ErrShortBuffer is returned. PatternInto does not allocate for values of up to 256 bytes.
+func (g *GeneratorLite) PatternInto(dst []uint64, value string) error {
+	if len(dst) < g.size {
+		return io.ErrShortBuffer
+	}
+	var buf [256]byte
+	hash := g.split.patternHash(sha256.Sum256(append(buf[:0], value...)))
+
+	var src liteSource
+	src.seed(g.prng, hash)
+	if g.prng == PRNG_MATH_RAND {
+		defer mathRandSources.Put(src.source)
+	}
+
+	rows, half := dst[:g.size], g.size/2
+	clear(rows)
+	switch g.algo {
+	case ALGORITHM_1:
+		// Columns are drawn left to right.
+		for x := 0; x <= half; x++ {
+			for y := range rows {
+				if src.float64() < g.bias {
+					rows[y] |= 1 << x
+				}
+			}
+		}
+	case ALGORITHM_2:
+		// Rows are drawn bottom to top, starting one row below the pattern whose draws are discarded.
+		for y := g.size; y >= 0; y-- {
+			for x := 0; x <= half; x++ {
+				if src.float64() < g.bias && y < g.size {
+					rows[y] |= 1 << x
+				}
+			}
+		}
+	default:
+		// Every cell is drawn, so there is nothing to mirror.
+		for y := range rows {
+			for x := 0; x < g.size; x++ {
+				if src.float64() < g.bias {
+					rows[y] |= 1 << x
+				}
+			}
+		}
+		return nil
+	}
+	// The columns right of the center mirror the left ones.
+	for y := range rows {
+		for x := half + 1; x < g.size; x++ {
+			rows[y] |= rows[y] >> (g.size - x - 1) & 1 << x
+		}
+	}
+	return nil
+}
+
+// liteSource draws from the generator of a PRNG without boxing it in a rand.Source.
+type liteSource struct {
+	prng    PRNG
+	source  rand.Source
+	xoshiro xoshiro
+	pcg     pcg
+	chaCha8 chaCha8
+}
+
+func (s *liteSource) seed(prng PRNG, hash [sha256.Size]byte) {
+	s.prng = prng
+	switch prng {
+	case PRNG_MATH_RAND:
+		s.source = mathRandSource(hash)
+	case PRNG_PCG:
+		s.pcg = newPCG(hash)
+	case PRNG_CHACHA8:
+		s.chaCha8.seed(hash)
+	default:
+		s.xoshiro = newXoshiro(hash)
+	}
+}
+
+func (s *liteSource) int63() int64 {
+	switch s.prng {
+	case PRNG_MATH_RAND:
+		return s.source.Int63()
+	case PRNG_PCG:
+		return int64(s.pcg.Uint64() >> 1)
+	case PRNG_CHACHA8:
+		return int64(s.chaCha8.Uint64() >> 1)
+	default:
+		return int64(s.xoshiro.Uint64() >> 1)
+	}
+}
+
+// float64 returns the same numbers as rand.Rand.Float64 on the source.
+func (s *liteSource) float64() float64 {
+	for {
+		if f := float64(s.int63()) / (1 << 63); f < 1 {
+			return f
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package avatar
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPatternIntoMatchesPatternMatrix(t *testing.T) {
+	for _, algo := range []Algorithm{ALGORITHM_1, ALGORITHM_2, ALGORITHM_3} {
+		for _, prng := range []PRNG{PRNG_MATH_RAND, PRNG_XOSHIRO, PRNG_PCG, PRNG_CHACHA8} {
+			for _, pattern := range append(pixelPatterns, 64) {
+				opts := []CreateOption{WithAlgorithm(algo), WithPRNG(prng), WithPixelPattern(pattern), WithDimension(64)}
+				g, err := NewGeneratorLite(opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rows := make([]uint64, pattern)
+				for _, value := range invariantValues {
+					if err := g.PatternInto(rows, value); err != nil {
+						t.Fatal(err)
+					}
+					matrix, err := New(value, opts...).PatternMatrix()
+					if err != nil {
+						t.Fatal(err)
+					}
+					for y, cells := range matrix {
+						for x, filled := range cells {
+							if got := rows[y]>>x&1 == 1; got != filled {
+								t.Fatalf("algorithm %d, PRNG %d, pattern %d, %q: cell (%d,%d) got %t, want %t",
+									algo, prng, pattern, value, x, y, got, filled)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestNewGeneratorLiteRejectsLargePatterns(t *testing.T) {
+	_, err := NewGeneratorLite(WithPixelPattern(100), WithDimension(100))
+	if !errors.Is(err, ErrInvalidOption) || !errors.Is(err, ErrPatternTooLarge) {
+		t.Errorf("got %v, want ErrInvalidOption wrapping ErrPatternTooLarge", err)
+	}
+}
+
+func TestPatternIntoDoesNotAllocate(t *testing.T) {
+	g, err := NewGeneratorLite(WithPRNG(PRNG_XOSHIRO))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := make([]uint64, PIXEL_PATTERN_5)
+	if n := testing.AllocsPerRun(100, func() { g.PatternInto(rows, "jane@example.com") }); n != 0 {
+		t.Errorf("got %g allocations, want 0", n)
+	}
+}
@@ -0,0 +1,55 @@
+package avatar
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+
+	"golang.org/x/image/draw"
+)
+
+// Bounds of low quality image placeholders.
+const (
+	maxLQIPSize = 32
+	lqipColors  = 16
+)
+
+// WithLQIP sets AvatarResult.LQIP to a low quality image placeholder: the rendered avatar scaled down
+// to size by size pixels, e.g. 8, and encoded as a PNG of at most 16 colors with the best compression.
+// Pages inline it, e.g. with LQIPDataURI, and scale it up blurred until the full avatar has loaded.
+// Sizes from 1 to 32 are supported.
+func WithLQIP(size uint) func(a *Avatar) {
+	return func(a *Avatar) {
+		if size < 1 || size > maxLQIPSize {
+			a.err = fmt.Errorf("%w: %dpx placeholder, expected 1 to %dpx", ErrInvalidDimension, size, maxLQIPSize)
+			return
+		}
+		a.lqipSize = int(size)
+	}
+}
+
+// encodeLQIP returns the low quality image placeholder of the rendered image. Images that are not
+// square, such as cards and banners, keep their aspect ratio with the longer side scaled to the size.
+func (av *Avatar) encodeLQIP() ([]byte, error) {
+	bounds := av.image.Bounds()
+	scale := float64(av.lqipSize) / float64(max(bounds.Dx(), bounds.Dy()))
+	width, height := max(1, int(float64(bounds.Dx())*scale+0.5)), max(1, int(float64(bounds.Dy())*scale+0.5))
+	small := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(small, small.Bounds(), av.image, av.image.Bounds(), draw.Src, nil)
+	var buf bytes.Buffer
+	if err := encodePNG(&buf, quantize(small, lqipColors), 0, png.BestCompression); err != nil {
+		return nil, av.wrapErr(ErrEncode, "encode placeholder", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// LQIPDataURI returns the low quality image placeholder as a data URI for src attributes and
+// CSS backgrounds, or an empty string unless WithLQIP is set.
+func (r *AvatarResult) LQIPDataURI() string {
+	if len(r.LQIP) == 0 {
+		return ""
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(r.LQIP)
+}
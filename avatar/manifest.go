@@ -0,0 +1,158 @@
+package avatar
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// ManifestEntry describes a generated avatar in a manifest.
+type ManifestEntry struct {
+	Value string `json:"value"`
+	// Style identifies the options the avatar was rendered with, see Generator.Style.
+	Style string `json:"style"`
+	// Path is the path of the saved file, empty for OUTPUT_BUFFER.
+	Path        string `json:"path,omitempty"`
+	Fingerprint string `json:"fingerprint"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Bytes       int    `json:"bytes"`
+}
+
+// manifestHeader is the header row of CSV manifests.
+var manifestHeader = []string{"value", "style", "path", "fingerprint", "width", "height", "bytes"}
+
+// Manifest writes manifest entries of generated avatars. It is safe for concurrent use.
+type Manifest struct {
+	mu          sync.Mutex
+	w           io.Writer
+	format      ManifestFormat
+	wroteHeader bool
+}
+
+// WithManifest writes an entry for every encoded avatar to w, for ingestion into asset databases after
+// bulk runs. MANIFEST_JSON writes one JSON object per line, MANIFEST_CSV a CSV table with a header row.
+// The fingerprint is the one of Fingerprint. Pass the same option to every avatar of a run, e.g. to
+// NewGenerator, to collect them in one manifest; it is safe for concurrent use.
+func WithManifest(w io.Writer, format ManifestFormat) func(a *Avatar) {
+	m := NewManifest(w, format)
+	return func(a *Avatar) {
+		a.manifest = m
+	}
+}
+
+// NewManifest creates a Manifest writing to w, for tools that save the avatars of a Generator themselves.
+func NewManifest(w io.Writer, format ManifestFormat) *Manifest {
+	return &Manifest{w: w, format: format}
+}
+
+// NewManifestEntry returns the manifest entry of the avatar for value rendered in the given style,
+// encoded as data and saved to path.
+func NewManifestEntry(value, style, path string, data []byte) (ManifestEntry, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ManifestEntry{}, New(value).wrapErr(ErrEncode, "decode generated png", err)
+	}
+	return ManifestEntry{
+		Value:       value,
+		Style:       style,
+		Path:        path,
+		Fingerprint: Fingerprint(img),
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		Bytes:       len(data),
+	}, nil
+}
+
+// manifestEntry writes the manifest entry of the avatar encoded as data and saved to path,
+// unless m is nil.
+func (av *Avatar) manifestEntry(m *Manifest, path string, data []byte) error {
+	if m == nil {
+		return nil
+	}
+	entry, err := NewManifestEntry(av.value, styleFingerprint(av.styleKey(), av.theme), path, data)
+	if err != nil {
+		return err
+	}
+	if err := m.Write(entry); err != nil {
+		return av.wrapErr(ErrWrite, "write manifest entry", err)
+	}
+	return nil
+}
+
+// Write writes entry in the manifest format.
+func (m *Manifest) Write(entry ManifestEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.format != MANIFEST_CSV {
+		return json.NewEncoder(m.w).Encode(entry)
+	}
+	w := csv.NewWriter(m.w)
+	if !m.wroteHeader {
+		w.Write(manifestHeader)
+		m.wroteHeader = true
+	}
+	w.Write([]string{entry.Value, entry.Style, entry.Path, entry.Fingerprint,
+		strconv.Itoa(entry.Width), strconv.Itoa(entry.Height), strconv.Itoa(entry.Bytes)})
+	w.Flush()
+	return w.Error()
+}
+
+// ReadManifest reads the entries of a manifest written in the given format.
+func ReadManifest(r io.Reader, format ManifestFormat) ([]ManifestEntry, error) {
+	var entries []ManifestEntry
+	if format != MANIFEST_CSV {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var entry ManifestEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				return nil, fmt.Errorf("read manifest entry %d: %w", len(entries)+1, err)
+			}
+			entries = append(entries, entry)
+		}
+		return entries, scanner.Err()
+	}
+
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	for i, record := range records {
+		if i == 0 {
+			continue // header
+		}
+		if len(record) != len(manifestHeader) {
+			return nil, fmt.Errorf("read manifest entry %d: %d fields, expected %d", i, len(record), len(manifestHeader))
+		}
+		width, err1 := strconv.Atoi(record[4])
+		height, err2 := strconv.Atoi(record[5])
+		n, err3 := strconv.Atoi(record[6])
+		if err := errors.Join(err1, err2, err3); err != nil {
+			return nil, fmt.Errorf("read manifest entry %d: %w", i, err)
+		}
+		entries = append(entries, ManifestEntry{record[0], record[1], record[2], record[3], width, height, n})
+	}
+	return entries, nil
+}
+
+// styleFingerprint returns a short hex string identifying a style key and the theme, if any.
+func styleFingerprint(style string, theme *Theme) string {
+	if theme != nil {
+		style += "\x00" + theme.Name
+	}
+	sum := sha256.Sum256([]byte(style))
+	return hex.EncodeToString(sum[:8])
+}
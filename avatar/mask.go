@@ -0,0 +1,184 @@
+package avatar
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"golang.org/x/image/draw"
+)
+
+// squircleExponent is the superellipse exponent that approximates iOS style app icon shapes.
+const squircleExponent = 5
+
+// squircleSegments is the number of points used to approximate a superellipse.
+const squircleSegments = 256
+
+// maskSubsamples is the number of sub-scanlines per pixel row used to anti-alias mask edges.
+const maskSubsamples = 4
+
+// Point is a position relative to the avatar, where (0, 0) is the top left
+// and (1, 1) the bottom right corner.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// WithMaskPath clips the avatar to the polygon described by path, e.g. a hexagon or a shield.
+// The polygon is closed automatically and filled using the even-odd rule. Its edges are
+// anti-aliased at the output dimension and everything outside of it is transparent.
+func WithMaskPath(path []Point) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.maskPath = append([]Point(nil), path...)
+	}
+}
+
+// WithSquircleMask clips the avatar to a squircle, the superellipse shape of iOS style app icons.
+func WithSquircleMask() func(a *Avatar) {
+	return WithMaskPath(SquirclePath(squircleExponent))
+}
+
+// SquirclePath returns a superellipse |x|^n + |y|^n = 1 filling the avatar, for use with WithMaskPath.
+// An exponent of 2 gives a circle, higher exponents approach a square.
+func SquirclePath(exponent float64) []Point {
+	path := make([]Point, squircleSegments)
+	for i := range path {
+		angle := 2 * math.Pi * float64(i) / squircleSegments
+		cos, sin := math.Cos(angle), math.Sin(angle)
+		path[i] = Point{
+			X: 0.5 + 0.5*math.Copysign(math.Pow(math.Abs(cos), 2/exponent), cos),
+			Y: 0.5 + 0.5*math.Copysign(math.Pow(math.Abs(sin), 2/exponent), sin),
+		}
+	}
+	return path
+}
+
+// WithCircleMask clips the avatar to a circle.
+func WithCircleMask() func(a *Avatar) {
+	return WithMaskPath(SquirclePath(2))
+}
+
+// WithSafeArea sets how much of the pattern, in percent, must stay visible when a mask is active.
+// By default (100) the pattern is inset until it fits inside the mask, so no corner cells are clipped.
+// Lower values shrink the inset, 0 draws the pattern at full size and lets the mask clip it.
+func WithSafeArea(percent float64) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.safeArea = math.Max(0, math.Min(100, percent))
+	}
+}
+
+// patternRect returns the area of the output image the pattern is drawn into.
+func (av *Avatar) patternRect() image.Rectangle {
+	size := av.outputDimension()
+	full := image.Rect(0, 0, size, size)
+	if len(av.maskPath) == 0 || av.safeArea == 0 {
+		return full
+	}
+	inscribed := inscribedSquare(av.maskPath)
+	side := av.snap(int((inscribed+(1-av.safeArea/100)*(1-inscribed))*float64(size) + 0.5))
+	offset := (size - side) / 2
+	return image.Rect(offset, offset, offset+side, offset+side)
+}
+
+// inscribedSquare returns the relative side length of the largest square centered in the avatar
+// whose corners lie inside the polygon.
+func inscribedSquare(path []Point) float64 {
+	low, high := 0.0, 0.5
+	for i := 0; i < 20; i++ {
+		half := (low + high) / 2
+		inside := true
+		for _, corner := range []Point{{0.5 - half, 0.5 - half}, {0.5 + half, 0.5 - half}, {0.5 - half, 0.5 + half}, {0.5 + half, 0.5 + half}} {
+			if !insidePolygon(path, corner) {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			low = half
+		} else {
+			high = half
+		}
+	}
+	return 2 * low
+}
+
+// insidePolygon reports whether p lies inside the polygon using the even-odd rule.
+func insidePolygon(path []Point, p Point) bool {
+	inside := false
+	for i, a := range path {
+		b := path[(i+1)%len(path)]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < a.X+(p.Y-a.Y)/(b.Y-a.Y)*(b.X-a.X) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// applyMask clips the rendered image to the mask path.
+func (av *Avatar) applyMask() {
+	bounds := av.image.Bounds()
+	masked := image.NewRGBA(bounds)
+	draw.DrawMask(masked, bounds, av.image, bounds.Min, polygonMask(av.maskPath, bounds), bounds.Min, draw.Src)
+	av.image = masked
+}
+
+// polygonMask rasterizes a polygon given in relative coordinates into an alpha mask covering bounds.
+func polygonMask(path []Point, bounds image.Rectangle) *image.Alpha {
+	mask := image.NewAlpha(bounds)
+	if len(path) < 3 {
+		return mask
+	}
+	width, height := bounds.Dx(), bounds.Dy()
+	points := make([]Point, len(path))
+	for i, p := range path {
+		points[i] = Point{p.X * float64(width), p.Y * float64(height)}
+	}
+
+	coverage := make([]float64, width)
+	var crossings []float64
+	for y := 0; y < height; y++ {
+		for i := range coverage {
+			coverage[i] = 0
+		}
+		for s := 0; s < maskSubsamples; s++ {
+			scanY := float64(y) + (float64(s)+0.5)/maskSubsamples
+			crossings = crossings[:0]
+			for i, p := range points {
+				q := points[(i+1)%len(points)]
+				if (p.Y <= scanY) != (q.Y <= scanY) {
+					crossings = append(crossings, p.X+(scanY-p.Y)/(q.Y-p.Y)*(q.X-p.X))
+				}
+			}
+			sort.Float64s(crossings)
+			for i := 0; i+1 < len(crossings); i += 2 {
+				addSpan(coverage, crossings[i], crossings[i+1], 1.0/maskSubsamples)
+			}
+		}
+		for x, c := range coverage {
+			if c > 1 {
+				c = 1
+			}
+			mask.Pix[y*mask.Stride+x] = uint8(c*0xff + 0.5)
+		}
+	}
+	return mask
+}
+
+// addSpan adds weight times the covered fraction of each pixel between x0 and x1 to coverage.
+func addSpan(coverage []float64, x0, x1, weight float64) {
+	if x0 < 0 {
+		x0 = 0
+	}
+	if max := float64(len(coverage)); x1 > max {
+		x1 = max
+	}
+	for x0 < x1 {
+		pixel := int(x0)
+		end := float64(pixel + 1)
+		if end > x1 {
+			end = x1
+		}
+		coverage[pixel] += (end - x0) * weight
+		x0 = end
+	}
+}
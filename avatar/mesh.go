@@ -0,0 +1,148 @@
+package avatar
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Default dimensions of exported meshes in millimeters.
+const (
+	defaultMeshCellSize   = 5
+	defaultMeshCellHeight = 2
+	defaultMeshBaseHeight = 1
+)
+
+// MeshOptions sets the dimensions of exported meshes in millimeters. Zero values select the defaults
+// of 5 mm wide cells extruded by 2 mm from a 1 mm base plate. A negative BaseHeight omits the plate.
+type MeshOptions struct {
+	CellSize   float64
+	CellHeight float64
+	BaseHeight float64
+}
+
+// box is an axis aligned cuboid.
+type box struct {
+	min, max [3]float64
+}
+
+// boxFaces lists the corners of each face of a box counterclockwise seen from outside, where bit 0,
+// 1 and 2 of a corner index select the maximum x, y and z coordinate.
+var boxFaces = [6][4]int{
+	{0, 2, 3, 1}, // bottom
+	{4, 5, 7, 6}, // top
+	{0, 1, 5, 4}, // front
+	{2, 6, 7, 3}, // back
+	{0, 4, 6, 2}, // left
+	{1, 3, 7, 5}, // right
+}
+
+// boxNormals are the outward normals of boxFaces.
+var boxNormals = [6][3]float32{{0, 0, -1}, {0, 0, 1}, {0, -1, 0}, {0, 1, 0}, {-1, 0, 0}, {1, 0, 0}}
+
+// corner returns the corner of b with the given index.
+func (b box) corner(i int) [3]float64 {
+	var c [3]float64
+	for axis := range c {
+		c[axis] = b.min[axis]
+		if i&(1<<axis) != 0 {
+			c[axis] = b.max[axis]
+		}
+	}
+	return c
+}
+
+// meshBoxes returns the base plate and one box per filled cell, with the pattern's top row at the largest y.
+func (av *Avatar) meshBoxes(o MeshOptions) ([]box, error) {
+	if o.CellSize == 0 {
+		o.CellSize = defaultMeshCellSize
+	}
+	if o.CellHeight == 0 {
+		o.CellHeight = defaultMeshCellHeight
+	}
+	if o.BaseHeight == 0 {
+		o.BaseHeight = defaultMeshBaseHeight
+	}
+	if o.CellSize < 0 || o.CellHeight < 0 {
+		return nil, av.wrapErr(ErrInvalidOption, "build mesh",
+			fmt.Errorf("%w: cell size %g and height %g must be positive", ErrInvalidMeshOptions, o.CellSize, o.CellHeight))
+	}
+	matrix, err := av.PatternMatrix()
+	if err != nil {
+		return nil, err
+	}
+
+	size := float64(len(matrix))
+	base := math.Max(o.BaseHeight, 0)
+	var boxes []box
+	if o.BaseHeight > 0 {
+		boxes = append(boxes, box{max: [3]float64{size * o.CellSize, size * o.CellSize, base}})
+	}
+	for y, cells := range matrix {
+		top := (size - float64(y)) * o.CellSize
+		for x, filled := range cells {
+			if filled {
+				boxes = append(boxes, box{
+					min: [3]float64{float64(x) * o.CellSize, top - o.CellSize, base},
+					max: [3]float64{float64(x+1) * o.CellSize, top, base + o.CellHeight},
+				})
+			}
+		}
+	}
+	return boxes, nil
+}
+
+// WriteOBJ writes the pattern as a Wavefront OBJ mesh in which filled cells are cubes extruded
+// from a base plate, e.g. to 3D print a badge or keychain.
+func (av *Avatar) WriteOBJ(w io.Writer, o MeshOptions) error {
+	boxes, err := av.meshBoxes(o)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# godenticon %dx%d pattern\n", av.pixelPattern, av.pixelPattern)
+	for i, b := range boxes {
+		for c := 0; c < 8; c++ {
+			v := b.corner(c)
+			fmt.Fprintf(bw, "v %g %g %g\n", v[0], v[1], v[2])
+		}
+		for _, face := range boxFaces {
+			fmt.Fprintf(bw, "f %d %d %d %d\n", 8*i+face[0]+1, 8*i+face[1]+1, 8*i+face[2]+1, 8*i+face[3]+1)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return av.wrapErr(ErrWrite, "write obj", err)
+	}
+	return nil
+}
+
+// WriteSTL writes the same mesh as WriteOBJ in the binary STL format.
+func (av *Avatar) WriteSTL(w io.Writer, o MeshOptions) error {
+	boxes, err := av.meshBoxes(o)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	var header [80]byte
+	copy(header[:], fmt.Sprintf("godenticon %dx%d pattern", av.pixelPattern, av.pixelPattern))
+	bw.Write(header[:])
+	binary.Write(bw, binary.LittleEndian, uint32(len(boxes)*len(boxFaces)*2))
+	for _, b := range boxes {
+		for i, face := range boxFaces {
+			for _, triangle := range [2][3]int{{face[0], face[1], face[2]}, {face[0], face[2], face[3]}} {
+				binary.Write(bw, binary.LittleEndian, boxNormals[i])
+				for _, c := range triangle {
+					v := b.corner(c)
+					binary.Write(bw, binary.LittleEndian, [3]float32{float32(v[0]), float32(v[1]), float32(v[2])})
+				}
+				binary.Write(bw, binary.LittleEndian, uint16(0)) // attribute byte count
+			}
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return av.wrapErr(ErrWrite, "write stl", err)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package avatar
+
+import "image"
+
+// mapCanvas returns an RGBA image of the given size on the heap, as memory-mapped files are only
+// supported on Unix systems.
+func mapCanvas(dir string, width, height int) (*image.RGBA, func() error, error) {
+	return image.NewRGBA(image.Rect(0, 0, width, height)), func() error { return nil }, nil
+}
@@ -0,0 +1,31 @@
+//go:build unix
+
+package avatar
+
+import (
+	"image"
+	"os"
+	"syscall"
+)
+
+// mapCanvas returns an RGBA image of the given size backed by a memory-mapped temporary file in dir
+// instead of the Go heap, and a function unmapping it. The file is removed right away, its disk space
+// is freed once the image is unmapped.
+func mapCanvas(dir string, width, height int) (*image.RGBA, func() error, error) {
+	f, err := os.CreateTemp(dir, ".canvas-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	defer os.Remove(f.Name())
+	size := 4 * width * height
+	if err := f.Truncate(int64(size)); err != nil {
+		return nil, nil, err
+	}
+	pix, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	img := &image.RGBA{Pix: pix, Stride: 4 * width, Rect: image.Rect(0, 0, width, height)}
+	return img, func() error { return syscall.Munmap(pix) }, nil
+}
@@ -0,0 +1,216 @@
+package avatar
+
+import "image/color"
+
+// Options is a plain struct alternative to the functional create options, convenient to build
+// from decoded JSON, YAML or protobuf configuration. Zero valued fields keep the defaults used by New.
+type Options struct {
+	// SecurityProfile applies WithSecurityProfile before the other fields.
+	SecurityProfile bool         `json:"security_profile"`
+	PixelPattern    PixelPattern `json:"pixel_pattern"`
+	Algorithm       Algorithm    `json:"algorithm"`
+	PRNG            PRNG         `json:"prng"`
+	// SanitizeValue normalizes values before hashing, see WithValueSanitization.
+	SanitizeValue bool `json:"sanitize_value"`
+	// EmptyValuePolicy applies to empty and whitespace-only values, see WithEmptyValuePolicy.
+	EmptyValuePolicy EmptyValuePolicy `json:"empty_value_policy"`
+	// EntropySplit is a pair of pattern and color bits, see WithEntropySplit.
+	EntropySplit *[2]int `json:"entropy_split"`
+	Dimension    uint    `json:"dimension"`
+	ScaleFactor  float64 `json:"scale_factor"`
+	DPI          int     `json:"dpi"`
+	DarkMode     bool    `json:"dark_mode"`
+	// Palette is the name of a built-in palette, see PaletteNames.
+	Palette string `json:"palette"`
+	// PaletteColors are colors in the #RRGGBB notation, see WithPalette. They take precedence over Palette.
+	PaletteColors []string `json:"palette_colors"`
+	// BrandColor is a color in the #RRGGBB notation, see WithBrandColor.
+	BrandColor string     `json:"brand_color"`
+	ColorSpace ColorSpace `json:"color_space"`
+	// LightnessRange and SaturationRange are min and max pairs, see WithLightnessRange.
+	LightnessRange     *[2]float64 `json:"lightness_range"`
+	SaturationRange    *[2]float64 `json:"saturation_range"`
+	TwoTone            TwoTone     `json:"two_tone"`
+	GradientBackground bool        `json:"gradient_background"`
+	PlaceholderPhoto   bool        `json:"placeholder_photo"`
+	CellBevel          float64     `json:"cell_bevel"`
+	MaskPath           []Point     `json:"mask_path"`
+	// SafeArea is the safe area in percent, nil keeps the default of 100.
+	SafeArea   *float64 `json:"safe_area"`
+	MaxBytes   int      `json:"max_bytes"`
+	OutputType Output   `json:"output_type"`
+	Format     Format   `json:"format"`
+	OutputDir  string   `json:"output_dir"`
+	// JPEGQuality is the quality of JPEG avatars from 1 to 100, see WithJPEGQuality.
+	JPEGQuality int `json:"jpeg_quality"`
+	// ContentAddressedNames names saved files by their hash, see WithContentAddressedNames.
+	ContentAddressedNames bool  `json:"content_addressed_names"`
+	Alias                 Alias `json:"alias"`
+	SnapToGrid            bool  `json:"snap_to_grid"`
+	Supersampling         int   `json:"supersampling"`
+	BlurHash              bool  `json:"blur_hash"`
+	LQIPSize              uint  `json:"lqip_size"`
+	// FormatFallback encodes as PNG if Format is unavailable, see WithFormatFallback.
+	FormatFallback bool `json:"format_fallback"`
+	SVGVariables   bool `json:"svg_variables"`
+	// SVGTitle and SVGDesc label SVG output, see WithSVGTitle.
+	SVGTitle string `json:"svg_title"`
+	SVGDesc  string `json:"svg_desc"`
+	// ChecksumSidecar writes checksum files beside saved avatars, see WithChecksumSidecar.
+	ChecksumSidecar Checksum `json:"checksum_sidecar"`
+}
+
+// NewWithOptions creates and returns a new Avatar object with the specified value and options.
+func NewWithOptions(value string, o Options) *Avatar {
+	return New(value, o.CreateOptions()...)
+}
+
+// CreateOptions converts the options into the equivalent create options.
+func (o Options) CreateOptions() []CreateOption {
+	var opts []CreateOption
+	if o.SecurityProfile {
+		opts = append(opts, WithSecurityProfile())
+	}
+	if o.SanitizeValue {
+		opts = append(opts, WithValueSanitization())
+	}
+	if o.EmptyValuePolicy != 0 {
+		opts = append(opts, WithEmptyValuePolicy(o.EmptyValuePolicy))
+	}
+	if o.PixelPattern != 0 {
+		opts = append(opts, WithPixelPattern(o.PixelPattern))
+	}
+	if o.Algorithm != 0 {
+		opts = append(opts, WithAlgorithm(o.Algorithm))
+	}
+	if o.PRNG != 0 {
+		opts = append(opts, WithPRNG(o.PRNG))
+	}
+	if o.EntropySplit != nil {
+		opts = append(opts, WithEntropySplit(o.EntropySplit[0], o.EntropySplit[1]))
+	}
+	if o.Dimension != 0 {
+		opts = append(opts, WithDimension(o.Dimension))
+	}
+	if o.ScaleFactor != 0 {
+		opts = append(opts, WithScaleFactor(o.ScaleFactor))
+	}
+	if o.DPI != 0 {
+		opts = append(opts, WithDPI(o.DPI))
+	}
+	if o.DarkMode {
+		opts = append(opts, WithDarkMode())
+	}
+	if o.Palette != "" {
+		opts = append(opts, WithPaletteByName(o.Palette))
+	}
+	if len(o.PaletteColors) > 0 {
+		opts = append(opts, withPaletteHex(o.PaletteColors))
+	}
+	if o.BrandColor != "" {
+		opts = append(opts, withBrandColorHex(o.BrandColor))
+	}
+	if o.ColorSpace != 0 {
+		opts = append(opts, WithColorSpace(o.ColorSpace))
+	}
+	if o.LightnessRange != nil {
+		opts = append(opts, WithLightnessRange(o.LightnessRange[0], o.LightnessRange[1]))
+	}
+	if o.SaturationRange != nil {
+		opts = append(opts, WithSaturationRange(o.SaturationRange[0], o.SaturationRange[1]))
+	}
+	if o.TwoTone != 0 {
+		opts = append(opts, WithTwoTone(o.TwoTone))
+	}
+	if o.GradientBackground {
+		opts = append(opts, WithGradientBackground())
+	}
+	if o.PlaceholderPhoto {
+		opts = append(opts, WithPlaceholderPhoto())
+	}
+	if o.CellBevel != 0 {
+		opts = append(opts, WithCellBevel(o.CellBevel))
+	}
+	if len(o.MaskPath) > 0 {
+		opts = append(opts, WithMaskPath(o.MaskPath))
+	}
+	if o.SafeArea != nil {
+		opts = append(opts, WithSafeArea(*o.SafeArea))
+	}
+	if o.MaxBytes != 0 {
+		opts = append(opts, WithMaxBytes(o.MaxBytes))
+	}
+	if o.OutputType != 0 {
+		opts = append(opts, WithOutputType(o.OutputType))
+	}
+	if o.OutputDir != "" {
+		opts = append(opts, WithOutputDir(o.OutputDir))
+	}
+	if o.ContentAddressedNames {
+		opts = append(opts, WithContentAddressedNames())
+	}
+	if o.Alias != 0 {
+		opts = append(opts, WithAlias(o.Alias))
+	}
+	if o.ChecksumSidecar != 0 {
+		opts = append(opts, WithChecksumSidecar(o.ChecksumSidecar))
+	}
+	if o.Format != 0 {
+		opts = append(opts, WithFormat(o.Format))
+	}
+	if o.JPEGQuality != 0 {
+		opts = append(opts, WithJPEGQuality(o.JPEGQuality))
+	}
+	if o.FormatFallback {
+		opts = append(opts, WithFormatFallback(nil))
+	}
+	if o.SVGVariables {
+		opts = append(opts, WithSVGVariables())
+	}
+	if o.SVGTitle != "" || o.SVGDesc != "" {
+		opts = append(opts, WithSVGTitle(o.SVGTitle, o.SVGDesc))
+	}
+	if o.SnapToGrid {
+		opts = append(opts, WithSnapToGrid())
+	}
+	if o.Supersampling != 0 {
+		opts = append(opts, WithSupersampling(o.Supersampling))
+	}
+	if o.BlurHash {
+		opts = append(opts, WithBlurHash())
+	}
+	if o.LQIPSize != 0 {
+		opts = append(opts, WithLQIP(o.LQIPSize))
+	}
+	return opts
+}
+
+// withBrandColorHex sets the brand color from the #RRGGBB notation.
+// Generate returns ErrInvalidColor for malformed colors.
+func withBrandColorHex(hex string) func(a *Avatar) {
+	return func(a *Avatar) {
+		c, err := parseHexColor(hex)
+		if err != nil {
+			a.err = err
+			return
+		}
+		a.brandColor = c
+	}
+}
+
+// withPaletteHex sets the palette from colors in the #RRGGBB notation.
+// Generate returns ErrInvalidColor for malformed colors.
+func withPaletteHex(hexes []string) func(a *Avatar) {
+	return func(a *Avatar) {
+		palette := make([]color.Color, len(hexes))
+		for i, hex := range hexes {
+			c, err := parseHexColor(hex)
+			if err != nil {
+				a.err = err
+				return
+			}
+			palette[i] = c
+		}
+		a.palette = palette
+	}
+}
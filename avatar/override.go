@@ -0,0 +1,115 @@
+package avatar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"sort"
+
+	"golang.org/x/image/draw"
+)
+
+// OverrideSpec replaces the identicon of a reserved value, see WithOverrides.
+type OverrideSpec struct {
+	// Image, when set, is shown instead of the pixel pattern, scaled to the output dimension.
+	// Masks, overlays and decorations still apply.
+	Image image.Image
+	// Options are applied on top of the options of the avatar, e.g. WithFillColor or WithPlaceholderPhoto.
+	Options []CreateOption
+}
+
+// WithOverrides renders the values in overrides, e.g. "admin", "system" or the sentinel of deleted
+// users, with their OverrideSpec instead of the hash derived identicon. Values are matched exactly,
+// after WithValueSanitization if it is set, and overrides take precedence over WithEmptyValuePolicy.
+// SVG output embeds override images as PNG, FORMAT_HTML tables show them at the size of the pattern.
+// Changing the images after passing them to WithOverrides is not supported.
+func WithOverrides(overrides map[string]OverrideSpec) func(a *Avatar) {
+	copied := make(map[string]OverrideSpec, len(overrides))
+	for value, spec := range overrides {
+		copied[value] = spec
+	}
+	key := overridesKey(copied)
+	return func(a *Avatar) {
+		a.overrides, a.overridesKey = copied, key
+	}
+}
+
+// applyOverride applies the override of the value, if there is one. The overrides are dropped once
+// applied, so options of the spec cannot apply twice.
+func (av *Avatar) applyOverride() {
+	spec, ok := av.overrides[av.value]
+	if !ok {
+		return
+	}
+	av.overrides, av.overridden = nil, true
+	for _, opt := range spec.Options {
+		opt(av)
+	}
+	if spec.Image != nil {
+		av.overrideImage = spec.Image
+	}
+}
+
+// drawOverrideImage scales the override image to the output dimension.
+func (av *Avatar) drawOverrideImage() {
+	size := av.outputDimension()
+	canvas := av.canvases.get(size)
+	draw.CatmullRom.Scale(canvas, canvas.Bounds(), av.overrideImage, av.overrideImage.Bounds(), draw.Src, nil)
+	av.image = canvas
+}
+
+// writeOverrideSVG writes the rendered override image as an embedded PNG.
+func (av *Avatar) writeOverrideSVG(w io.Writer) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, av.image); err != nil {
+		return err
+	}
+	size := av.outputDimension()
+	_, err := fmt.Fprintf(w, `<image width="%d" height="%d" href="data:image/png;base64,%s"/>`,
+		size, size, base64.StdEncoding.EncodeToString(buf.Bytes()))
+	return err
+}
+
+// overridePattern returns the rendered override image scaled down to one pixel per cell of the pattern.
+func (av *Avatar) overridePattern(cells int) *image.RGBA {
+	pattern := image.NewRGBA(image.Rect(0, 0, cells, cells))
+	draw.ApproxBiLinear.Scale(pattern, pattern.Bounds(), av.image, av.image.Bounds(), draw.Src, nil)
+	return pattern
+}
+
+// overridesKey returns a style key part identifying the overrides by their values, the pixels of their
+// images and the style of their options.
+func overridesKey(overrides map[string]OverrideSpec) string {
+	if len(overrides) == 0 {
+		return ""
+	}
+	values := make([]string, 0, len(overrides))
+	for value := range overrides {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	h := sha256.New()
+	for _, value := range values {
+		spec := overrides[value]
+		fmt.Fprintf(h, "%q %s\x00", value, New(value, spec.Options...).styleKey())
+		if spec.Image != nil {
+			bounds := spec.Image.Bounds()
+			fmt.Fprintf(h, "%v", bounds)
+			var pixel [8]byte
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				for x := bounds.Min.X; x < bounds.Max.X; x++ {
+					r, g, b, a := spec.Image.At(x, y).RGBA()
+					binary.BigEndian.PutUint64(pixel[:], uint64(r)<<48|uint64(g)<<32|uint64(b)<<16|uint64(a))
+					h.Write(pixel[:])
+				}
+			}
+		}
+	}
+	return "-overrides" + hex.EncodeToString(h.Sum(nil)[:8])
+}
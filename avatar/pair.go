@@ -0,0 +1,40 @@
+package avatar
+
+import (
+	"image"
+)
+
+// GeneratePairVisualization creates a single avatar interleaving the patterns and colors of two values
+// in a checkerboard of pattern cells, e.g. to visualize a connection or key exchange between two parties.
+// Cells alternate starting with a in the top left corner, so swapping a and b yields a different image.
+// The options apply to the combined avatar.
+func GeneratePairVisualization(a, b string, opts ...CreateOption) (*AvatarResult, error) {
+	pair := New(a+"\x00"+b, opts...)
+	if err := pair.validate(); err != nil {
+		return nil, err
+	}
+	size := pair.outputDimension()
+	first, err := pair.renderMember(a, size)
+	if err != nil {
+		return nil, err
+	}
+	second, err := pair.renderMember(b, size)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := int(pair.pixelPattern)
+	pair.image = image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			cellX, cellY := x*pattern/size, y*pattern/size
+			if (cellX+cellY)%2 == 0 {
+				pair.image.Set(x, y, first.image.At(x, y))
+			} else {
+				pair.image.Set(x, y, second.image.At(x, y))
+			}
+		}
+	}
+
+	return pair.output()
+}
@@ -0,0 +1,117 @@
+package avatar
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+)
+
+// palettePacks contains the built-in palettes selectable with WithPaletteByName.
+var palettePacks = map[string][]color.Color{
+	"material": hexColors(
+		"#F44336", "#E91E63", "#9C27B0", "#673AB7", "#3F51B5", "#2196F3",
+		"#03A9F4", "#00BCD4", "#009688", "#4CAF50", "#8BC34A", "#CDDC39",
+		"#FFEB3B", "#FFC107", "#FF9800", "#FF5722", "#795548", "#607D8B",
+	),
+	"tailwind": hexColors(
+		"#EF4444", "#F97316", "#F59E0B", "#EAB308", "#84CC16", "#22C55E",
+		"#10B981", "#14B8A6", "#06B6D4", "#0EA5E9", "#3B82F6", "#6366F1",
+		"#8B5CF6", "#A855F7", "#D946EF", "#EC4899", "#F43F5E",
+	),
+	"solarized": hexColors(
+		"#B58900", "#CB4B16", "#DC322F", "#D33682",
+		"#6C71C4", "#268BD2", "#2AA198", "#859900",
+	),
+	"nord": hexColors(
+		"#8FBCBB", "#88C0D0", "#81A1C1", "#5E81AC",
+		"#BF616A", "#D08770", "#EBCB8B", "#A3BE8C", "#B48EAD",
+	),
+	"catppuccin": hexColors(
+		"#F5E0DC", "#F2CDCD", "#F5C2E7", "#CBA6F7", "#F38BA8", "#EBA0AC", "#FAB387",
+		"#F9E2AF", "#A6E3A1", "#94E2D5", "#89DCEB", "#74C7EC", "#89B4FA", "#B4BEFE",
+	),
+}
+
+// PaletteNames returns the names of the built-in palettes in alphabetical order.
+func PaletteNames() []string {
+	names := make([]string, 0, len(palettePacks))
+	for name := range palettePacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithPaletteByName sets the fill color of the avatar to one of the colors of a built-in palette.
+// The color is picked deterministically from the value hash. Names are case-insensitive,
+// see PaletteNames for the available palettes. Generate returns ErrUnknownPalette for unknown names.
+func WithPaletteByName(name string) func(a *Avatar) {
+	return func(a *Avatar) {
+		palette, ok := palettePacks[strings.ToLower(name)]
+		if !ok {
+			a.err = ErrUnknownPalette
+			return
+		}
+		a.palette = palette
+	}
+}
+
+// WithPalette sets the fill color of the avatar to one of the given colors, e.g. of a product's brand
+// palette, picked deterministically from the value hash like the colors of WithPaletteByName.
+// Generate returns ErrInvalidOption wrapping ErrEmptyPalette if no colors are given
+// and wrapping ErrInvalidColor if a color is nil.
+func WithPalette(colors []color.Color) func(a *Avatar) {
+	palette := append([]color.Color(nil), colors...)
+	return func(a *Avatar) {
+		if len(palette) == 0 {
+			a.err = ErrEmptyPalette
+			return
+		}
+		for i, c := range palette {
+			if c == nil {
+				a.err = fmt.Errorf("%w: palette color %d is nil", ErrInvalidColor, i)
+				return
+			}
+		}
+		a.palette = palette
+	}
+}
+
+// hexColors parses colors in the #RRGGBB notation.
+func hexColors(hexes ...string) []color.Color {
+	colors := make([]color.Color, len(hexes))
+	for i, hex := range hexes {
+		colors[i], _ = parseHexColor(hex)
+	}
+	return colors
+}
+
+// parseHexColor parses an opaque color in the #RRGGBB notation.
+func parseHexColor(hex string) (color.RGBA, error) {
+	if len(hex) != 7 || hex[0] != '#' {
+		return color.RGBA{}, ErrInvalidColor
+	}
+	var rgb [3]uint8
+	for i := range rgb {
+		high, ok1 := hexDigit(hex[1+2*i])
+		low, ok2 := hexDigit(hex[2+2*i])
+		if !ok1 || !ok2 {
+			return color.RGBA{}, ErrInvalidColor
+		}
+		rgb[i] = high<<4 | low
+	}
+	return color.RGBA{rgb[0], rgb[1], rgb[2], 0xff}, nil
+}
+
+func hexDigit(c byte) (uint8, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
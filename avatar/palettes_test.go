@@ -0,0 +1,25 @@
+package avatar
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+)
+
+func TestWithPalette(t *testing.T) {
+	for name, tc := range map[string]struct {
+		colors []color.Color
+		want   error
+	}{
+		"empty":     {nil, ErrEmptyPalette},
+		"nil color": {[]color.Color{color.White, nil}, ErrInvalidColor},
+	} {
+		_, err := New("octocat", WithPalette(tc.colors), WithOutputType(OUTPUT_BUFFER)).Generate()
+		if !errors.Is(err, ErrInvalidOption) || !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want ErrInvalidOption wrapping %v", name, err, tc.want)
+		}
+	}
+	if _, err := New("octocat", WithPalette([]color.Color{color.White, color.Black}), WithOutputType(OUTPUT_BUFFER)).Generate(); err != nil {
+		t.Errorf("valid palette: %v", err)
+	}
+}
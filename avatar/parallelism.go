@@ -0,0 +1,22 @@
+package avatar
+
+import "fmt"
+
+// WithMaxParallelism limits the avatars rendered and encoded at the same time to n, further calls of
+// Generate wait for a running one to finish, so spikes of avatar generation cannot starve the other
+// handlers of the host application of CPU. The limit is shared by all avatars the returned option is
+// applied to: pass the same option to every Generator, e.g. from a package level variable, to limit
+// them together.
+func WithMaxParallelism(n int) func(a *Avatar) {
+	var limiter chan struct{}
+	if n >= 1 {
+		limiter = make(chan struct{}, n)
+	}
+	return func(a *Avatar) {
+		if limiter == nil {
+			a.err = fmt.Errorf("%w: %d", ErrInvalidParallelism, n)
+			return
+		}
+		a.limiter = limiter
+	}
+}
@@ -0,0 +1,94 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"image/color"
+	"strconv"
+)
+
+// colorBands are the ranges lightness and chroma of derived colors are picked from.
+type colorBands struct {
+	minLightness, maxLightness float64
+	minChroma, maxChroma       float64
+}
+
+// defaultColorBands keep derived colors of every hue at a similar, medium brightness.
+var defaultColorBands = map[ColorSpace]colorBands{
+	COLOR_SPACE_OKLCH:  {0.60, 0.75, 0.10, 0.16},
+	COLOR_SPACE_CIELCH: {55, 70, 35, 55},
+}
+
+// maxColorBands are the largest lightness and chroma accepted by WithColorBands.
+var maxColorBands = map[ColorSpace]colorBands{
+	COLOR_SPACE_OKLCH:  {1, 1, 0.4, 0.4},
+	COLOR_SPACE_CIELCH: {100, 100, 150, 150},
+}
+
+// WithColorSpace derives fill colors in a perceptually uniform color space, so avatars of all hues
+// appear equally bright. COLOR_SPACE_RGB keeps the original derivation from the RGB channels.
+// Brand colors and palettes take precedence over the color space.
+func WithColorSpace(space ColorSpace) func(a *Avatar) {
+	return func(a *Avatar) {
+		if _, ok := defaultColorBands[space]; !ok && space != COLOR_SPACE_RGB {
+			a.err = fmt.Errorf("%w: color space %d", ErrInvalidColorBands, space)
+			return
+		}
+		a.colorSpace = space
+	}
+}
+
+// WithColorBands sets the ranges lightness and chroma of colors derived with WithColorSpace are picked
+// from, in the units of the color space: lightness 0-1 and chroma 0-0.4 for COLOR_SPACE_OKLCH,
+// lightness 0-100 and chroma 0-150 for COLOR_SPACE_CIELCH. Set the color space first.
+// Chroma is lowered where a color would fall outside the sRGB gamut.
+func WithColorBands(minLightness, maxLightness, minChroma, maxChroma float64) func(a *Avatar) {
+	return func(a *Avatar) {
+		limits, ok := maxColorBands[a.colorSpace]
+		if !ok || minLightness < 0 || minLightness > maxLightness || maxLightness > limits.maxLightness ||
+			minChroma < 0 || minChroma > maxChroma || maxChroma > limits.maxChroma {
+			a.err = fmt.Errorf("%w: lightness %g-%g, chroma %g-%g", ErrInvalidColorBands, minLightness, maxLightness, minChroma, maxChroma)
+			return
+		}
+		a.colorBands = &colorBands{minLightness, maxLightness, minChroma, maxChroma}
+	}
+}
+
+// perceptualColor derives the fill color in the configured color space.
+func (av *Avatar) perceptualColor(hash [sha256.Size]byte) color.Color {
+	bands := defaultColorBands[av.colorSpace]
+	if av.colorBands != nil {
+		bands = *av.colorBands
+	}
+	toRGB := oklchToRGB
+	if av.colorSpace == COLOR_SPACE_CIELCH {
+		toRGB = cielchToRGB
+	}
+
+	h := hashFraction(hash[0:4]) * 360
+	l := bands.minLightness + hashFraction(hash[4:8])*(bands.maxLightness-bands.minLightness)
+	c := bands.minChroma + hashFraction(hash[8:12])*(bands.maxChroma-bands.minChroma)
+	if r, g, b := toRGB(l, c, h); inGamut(r, g, b) {
+		return linearToRGBA(r, g, b)
+	}
+	// Find the largest chroma that is still displayable.
+	low, high := 0.0, c
+	for i := 0; i < 24; i++ {
+		mid := (low + high) / 2
+		if r, g, b := toRGB(l, mid, h); inGamut(r, g, b) {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return linearToRGBA(toRGB(l, low, h))
+}
+
+// key returns a string identifying the color bands.
+func (b *colorBands) key() string {
+	if b == nil {
+		return ""
+	}
+	return "-" + strconv.FormatFloat(b.minLightness, 'g', -1, 64) + "," + strconv.FormatFloat(b.maxLightness, 'g', -1, 64) +
+		"," + strconv.FormatFloat(b.minChroma, 'g', -1, 64) + "," + strconv.FormatFloat(b.maxChroma, 'g', -1, 64)
+}
@@ -0,0 +1,166 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Number of soft color blobs of placeholder photos.
+const (
+	minPhotoBlobs   = 4
+	photoBlobsRange = 3
+)
+
+// WithPlaceholderPhoto renders an abstract "lorem avatar" instead of the pixel pattern: soft radial
+// gradients of hash derived colors over a muted base color, which reads like an out of focus photo
+// in design mockups where blocky identicons look too technical. Masks, overlays and decorations still
+// apply, the pattern effects do not. SVG and inline CSS output show the same gradients, FORMAT_HTML
+// tables only the base color.
+func WithPlaceholderPhoto() func(a *Avatar) {
+	return func(a *Avatar) {
+		a.placeholderPhoto = true
+	}
+}
+
+// photoStop is a color stop of a blob gradient, offset runs from 0 at the center to 1 at the edge.
+type photoStop struct {
+	offset float64
+	color  color.NRGBA
+}
+
+// photoBlob is a radial gradient, with center and radius as fractions of the image size.
+type photoBlob struct {
+	x, y, radius float64
+	stops        [3]photoStop
+}
+
+// photoBlobs derives the base color and the blobs of a placeholder photo from the value hash,
+// their positions from the pattern bits and their colors from the color bits.
+func (av *Avatar) photoBlobs(hash [sha256.Size]byte) (color.RGBA, []photoBlob) {
+	shapes, colors := av.entropySplit.patternHash(hash), av.entropySplit.colorHash(hash)
+	lightness := 0.6
+	if av.darkMode {
+		lightness = 0.3
+	}
+	hue := hashFraction(colors[0:4]) * 360
+	base := fromHSL(hue, 0.35, lightness)
+
+	blobs := make([]photoBlob, minPhotoBlobs+int(shapes[0])%photoBlobsRange)
+	for i := range blobs {
+		// The hash has too few bits for all blobs, so every blob draws from a hash of its own.
+		shape := sha256.Sum256(append(shapes[:], byte(i)))
+		tint := sha256.Sum256(append(colors[:], byte(i)))
+		inner := fromHSL(hue+(hashFraction(tint[0:4])-0.5)*120, 0.5+hashFraction(tint[4:8])*0.3,
+			lightness-0.15+hashFraction(tint[8:12])*0.3)
+		outer := fromHSL(hue+(hashFraction(tint[12:16])-0.5)*160, 0.4+hashFraction(tint[16:20])*0.3,
+			lightness-0.1+hashFraction(tint[20:24])*0.2)
+		blobs[i] = photoBlob{
+			x:      hashFraction(shape[0:4]),
+			y:      hashFraction(shape[4:8]),
+			radius: 0.35 + hashFraction(shape[8:12])*0.45,
+			stops: [3]photoStop{
+				{0, color.NRGBA{inner.R, inner.G, inner.B, 0xe6}},
+				{0.45 + hashFraction(shape[12:16])*0.2, color.NRGBA{outer.R, outer.G, outer.B, 0x80}},
+				{1, color.NRGBA{outer.R, outer.G, outer.B, 0}},
+			},
+		}
+	}
+	return base, blobs
+}
+
+// at returns the color of the blob gradient at distance d from the center, relative to the radius.
+// Colors and opacities are interpolated separately like SVG gradient stops.
+func (b photoBlob) at(d float64) color.NRGBA {
+	for i := 1; i < len(b.stops); i++ {
+		from, to := b.stops[i-1], b.stops[i]
+		if d > to.offset {
+			continue
+		}
+		t := (d - from.offset) / (to.offset - from.offset)
+		lerp := func(a, b uint8) uint8 {
+			return uint8(float64(a) + (float64(b)-float64(a))*t + 0.5)
+		}
+		return color.NRGBA{lerp(from.color.R, to.color.R), lerp(from.color.G, to.color.G),
+			lerp(from.color.B, to.color.B), lerp(from.color.A, to.color.A)}
+	}
+	return color.NRGBA{}
+}
+
+// drawPlaceholderPhoto replaces av.image with the placeholder photo at the output dimension.
+func (av *Avatar) drawPlaceholderPhoto(hash [sha256.Size]byte) {
+	size := av.outputDimension()
+	base, blobs := av.photoBlobs(hash)
+	canvas := av.canvases.get(size)
+	pix := make([][3]float64, size*size)
+	for i := range pix {
+		pix[i] = [3]float64{float64(base.R), float64(base.G), float64(base.B)}
+	}
+	for _, blob := range blobs {
+		cx, cy, r := blob.x*float64(size), blob.y*float64(size), blob.radius*float64(size)
+		for y := max(0, int(cy-r)); y < min(size, int(cy+r)+1); y++ {
+			for x := max(0, int(cx-r)); x < min(size, int(cx+r)+1); x++ {
+				d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy) / r
+				if d >= 1 {
+					continue
+				}
+				c := blob.at(d)
+				alpha := float64(c.A) / 0xff
+				p := &pix[y*size+x]
+				p[0] += (float64(c.R) - p[0]) * alpha
+				p[1] += (float64(c.G) - p[1]) * alpha
+				p[2] += (float64(c.B) - p[2]) * alpha
+			}
+		}
+	}
+	for i, p := range pix {
+		canvas.SetRGBA(i%size, i/size, color.RGBA{uint8(p[0] + 0.5), uint8(p[1] + 0.5), uint8(p[2] + 0.5), 0xff})
+	}
+	av.image = canvas
+}
+
+// writePhotoSVG writes the placeholder photo as a background rect and a circle per blob, filled with
+// radial gradients whose ids are prefixed with id.
+func (av *Avatar) writePhotoSVG(w io.Writer, hash [sha256.Size]byte, id string) {
+	size := float64(av.outputDimension())
+	base, blobs := av.photoBlobs(hash)
+	io.WriteString(w, "<defs>")
+	for i, blob := range blobs {
+		fmt.Fprintf(w, `<radialGradient id="%s-blob-%d">`, id, i)
+		for _, stop := range blob.stops {
+			fmt.Fprintf(w, `<stop offset="%s" stop-color="#%02x%02x%02x" stop-opacity="%s"/>`,
+				strconv.FormatFloat(stop.offset, 'f', 3, 64), stop.color.R, stop.color.G, stop.color.B,
+				strconv.FormatFloat(float64(stop.color.A)/0xff, 'f', 3, 64))
+		}
+		io.WriteString(w, "</radialGradient>")
+	}
+	io.WriteString(w, "</defs>")
+	fmt.Fprintf(w, `<rect width="%d" height="%d"%s/>`, int(size), int(size), av.svgFill(color.NRGBA(base), svgBackgroundVariable))
+	for i, blob := range blobs {
+		fmt.Fprintf(w, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="url(#%s-blob-%d)"/>`,
+			blob.x*size, blob.y*size, blob.radius*size, id, i)
+	}
+}
+
+// photoCSS returns the placeholder photo as a CSS background of one radial gradient per blob over the base color.
+func (av *Avatar) photoCSS(hash [sha256.Size]byte) string {
+	size := float64(av.outputDimension())
+	base, blobs := av.photoBlobs(hash)
+	// The first layer is drawn on top, so the blobs are listed last to first.
+	layers := make([]string, 0, len(blobs)+1)
+	for i := len(blobs) - 1; i >= 0; i-- {
+		blob := blobs[i]
+		stops := make([]string, len(blob.stops))
+		for j, stop := range blob.stops {
+			stops[j] = cssColor(stop.color) + " " + strconv.FormatFloat(stop.offset*100, 'f', 1, 64) + "%"
+		}
+		layers = append(layers, fmt.Sprintf("radial-gradient(circle %.1fpx at %.1fpx %.1fpx,%s)",
+			blob.radius*size, blob.x*size, blob.y*size, strings.Join(stops, ",")))
+	}
+	layers = append(layers, cssColor(base))
+	return fmt.Sprintf("display:inline-block;width:%dpx;height:%dpx;background:%s;", int(size), int(size), strings.Join(layers, ","))
+}
@@ -0,0 +1,174 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+	"math/rand"
+	"sync"
+)
+
+// uint64Generator is a pseudo random number generator producing 64 bits at a time.
+type uint64Generator interface {
+	Uint64() uint64
+}
+
+// generatorSource adapts a uint64Generator to a math/rand source.
+// Seed is a no-op, the generators are seeded from the value hash when created.
+type generatorSource struct {
+	uint64Generator
+}
+
+func (s generatorSource) Int63() int64 { return int64(s.Uint64() >> 1) }
+
+func (s generatorSource) Seed(int64) {}
+
+// mathRandSources holds math/rand sources for PRNG_MATH_RAND, which are large to allocate.
+var mathRandSources = sync.Pool{New: func() any { return rand.NewSource(0) }}
+
+// mathRandSource returns a math/rand source from mathRandSources seeded with the first four bytes
+// of the hash read big endian. Avatars get their own source, so concurrent generations neither
+// serialize nor race with other users of the global source, and draw the sequence the global
+// source drew when it was seeded per avatar. Put it back into mathRandSources when done.
+func mathRandSource(hash [sha256.Size]byte) rand.Source {
+	src := mathRandSources.Get().(rand.Source)
+	src.Seed(int64(binary.BigEndian.Uint32(hash[:])))
+	return src
+}
+
+// newPRNGSource returns a source of the given generator seeded from the value hash.
+func newPRNGSource(prng PRNG, hash [sha256.Size]byte) rand.Source {
+	switch prng {
+	case PRNG_PCG:
+		p := newPCG(hash)
+		return generatorSource{&p}
+	case PRNG_CHACHA8:
+		return generatorSource{newChaCha8(hash)}
+	default:
+		x := newXoshiro(hash)
+		return generatorSource{&x}
+	}
+}
+
+// xoshiro is the xoshiro256** generator.
+type xoshiro [4]uint64
+
+func (x *xoshiro) Uint64() uint64 {
+	result := bits.RotateLeft64(x[1]*5, 7) * 9
+	t := x[1] << 17
+	x[2] ^= x[0]
+	x[3] ^= x[1]
+	x[1] ^= x[2]
+	x[0] ^= x[3]
+	x[2] ^= t
+	x[3] = bits.RotateLeft64(x[3], 45)
+	return result
+}
+
+func newXoshiro(hash [sha256.Size]byte) xoshiro {
+	return xoshiro{
+		binary.LittleEndian.Uint64(hash[0:8]),
+		binary.LittleEndian.Uint64(hash[8:16]),
+		binary.LittleEndian.Uint64(hash[16:24]),
+		binary.LittleEndian.Uint64(hash[24:32]) | 1,
+	}
+}
+
+// pcg is a 128-bit permuted congruential generator with the DXSM output function.
+type pcg struct {
+	hi, lo uint64
+}
+
+func newPCG(hash [sha256.Size]byte) pcg {
+	return pcg{
+		hi: binary.LittleEndian.Uint64(hash[0:8]),
+		lo: binary.LittleEndian.Uint64(hash[8:16]),
+	}
+}
+
+func (p *pcg) Uint64() uint64 {
+	const (
+		mulHi = 2549297995355413924
+		mulLo = 4865540595714422341
+		incHi = 6364136223846793005
+		incLo = 1442695040888963407
+	)
+	hi, lo := bits.Mul64(p.lo, mulLo)
+	hi += p.hi*mulLo + p.lo*mulHi
+	lo, carry := bits.Add64(lo, incLo, 0)
+	hi, _ = bits.Add64(hi, incHi, carry)
+	p.hi, p.lo = hi, lo
+
+	const cheapMul = 0xda942042e4dd58b5
+	hi ^= hi >> 32
+	hi *= cheapMul
+	hi ^= hi >> 48
+	return hi * (lo | 1)
+}
+
+// chaCha8 is a generator returning the key stream of the ChaCha cipher reduced to 8 rounds.
+type chaCha8 struct {
+	key     [8]uint32
+	counter uint64
+	block   [16]uint32
+	next    int
+}
+
+func newChaCha8(seed [sha256.Size]byte) *chaCha8 {
+	c := new(chaCha8)
+	c.seed(seed)
+	return c
+}
+
+// seed resets c to the start of the key stream for the given key.
+func (c *chaCha8) seed(seed [sha256.Size]byte) {
+	*c = chaCha8{next: len(c.block)}
+	for i := range c.key {
+		c.key[i] = binary.LittleEndian.Uint32(seed[4*i:])
+	}
+}
+
+func (c *chaCha8) Uint64() uint64 {
+	if c.next+2 > len(c.block) {
+		c.refill()
+	}
+	v := uint64(c.block[c.next]) | uint64(c.block[c.next+1])<<32
+	c.next += 2
+	return v
+}
+
+// refill computes the next key stream block.
+func (c *chaCha8) refill() {
+	state := [16]uint32{
+		0x61707865, 0x3320646e, 0x79622d32, 0x6b206574,
+		c.key[0], c.key[1], c.key[2], c.key[3],
+		c.key[4], c.key[5], c.key[6], c.key[7],
+		uint32(c.counter), uint32(c.counter >> 32), 0, 0,
+	}
+	x := state
+	quarterRound := func(a, b, c, d int) {
+		x[a] += x[b]
+		x[d] = bits.RotateLeft32(x[d]^x[a], 16)
+		x[c] += x[d]
+		x[b] = bits.RotateLeft32(x[b]^x[c], 12)
+		x[a] += x[b]
+		x[d] = bits.RotateLeft32(x[d]^x[a], 8)
+		x[c] += x[d]
+		x[b] = bits.RotateLeft32(x[b]^x[c], 7)
+	}
+	for i := 0; i < 8; i += 2 {
+		quarterRound(0, 4, 8, 12)
+		quarterRound(1, 5, 9, 13)
+		quarterRound(2, 6, 10, 14)
+		quarterRound(3, 7, 11, 15)
+		quarterRound(0, 5, 10, 15)
+		quarterRound(1, 6, 11, 12)
+		quarterRound(2, 7, 8, 13)
+		quarterRound(3, 4, 9, 14)
+	}
+	for i := range x {
+		c.block[i] = x[i] + state[i]
+	}
+	c.counter++
+	c.next = 0
+}
@@ -0,0 +1,64 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+)
+
+// BenchmarkPRNG seeds a source per avatar and draws the cells of a PIXEL_PATTERN_12 pattern, like
+// drawPattern does. "global" is the rand.Seed path the per avatar sources replaced and "unpooled"
+// allocates a math/rand source per avatar instead of taking it from mathRandSources.
+func BenchmarkPRNG(b *testing.B) {
+	const draws = 12 * 12
+	hash := sha256.Sum256([]byte("abhinavsingh"))
+	seed := int64(binary.BigEndian.Uint32(hash[:]))
+	// run draws from the sources returned by source and calls done after the draws of every avatar.
+	run := func(b *testing.B, source func() (r *rand.Rand, done func())) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r, done := source()
+			for j := 0; j < draws; j++ {
+				r.Float64()
+			}
+			done()
+		}
+	}
+	b.Run("global", func(b *testing.B) {
+		run(b, func() (*rand.Rand, func()) {
+			rand.Seed(seed)
+			return globalRand, func() {}
+		})
+	})
+	b.Run("unpooled", func(b *testing.B) {
+		run(b, func() (*rand.Rand, func()) {
+			return rand.New(rand.NewSource(seed)), func() {}
+		})
+	})
+	b.Run("math_rand", func(b *testing.B) {
+		run(b, func() (*rand.Rand, func()) {
+			src := mathRandSource(hash)
+			return rand.New(src), func() { mathRandSources.Put(src) }
+		})
+	})
+	for _, prng := range []struct {
+		name string
+		prng PRNG
+	}{{"xoshiro", PRNG_XOSHIRO}, {"pcg", PRNG_PCG}, {"chacha8", PRNG_CHACHA8}} {
+		b.Run(prng.name, func(b *testing.B) {
+			run(b, func() (*rand.Rand, func()) {
+				return rand.New(newPRNGSource(prng.prng, hash)), func() {}
+			})
+		})
+	}
+}
+
+// globalRand draws from the global math/rand source.
+var globalRand = rand.New(globalSource{})
+
+type globalSource struct{}
+
+func (globalSource) Int63() int64 { return rand.Int63() }
+
+func (globalSource) Seed(seed int64) { rand.Seed(seed) }
@@ -0,0 +1,192 @@
+package avatar
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	modulePath = "github.com/bugcacher/godenticon"
+	// provenanceKeyword is the keyword of the tEXt chunk holding the provenance of PNGs.
+	provenanceKeyword = "godenticon:provenance"
+	// provenanceExtension is appended to the path of saved avatars for their provenance sidecar file.
+	provenanceExtension = ".provenance.json"
+)
+
+// Provenance is the generation metadata WithProvenance signs.
+type Provenance struct {
+	// ContentHash is the hex encoded SHA-256 of the encoded avatar, without the provenance chunk of PNGs.
+	ContentHash string `json:"content_sha256"`
+	// ValueHash is the hex encoded SHA-256 of the value, so the value itself is not disclosed.
+	ValueHash string `json:"value_sha256"`
+	// Style identifies the options the avatar was rendered with, as in manifests.
+	Style  string `json:"style"`
+	Format string `json:"format"`
+	// Version is the version of this module that generated the avatar, "(devel)" if unknown.
+	Version string    `json:"version"`
+	Time    time.Time `json:"time"`
+}
+
+// provenanceEnvelope is the signed form of a Provenance: the JSON encoded provenance and its Ed25519 signature.
+type provenanceEnvelope struct {
+	Payload   []byte `json:"payload"`
+	Signature []byte `json:"signature"`
+}
+
+// WithProvenance signs the generation metadata of every encoded avatar with key, so downstream systems
+// can check with VerifyProvenance that an avatar was produced by a trusted service. PNGs carry the signed
+// provenance in a tEXt chunk, other formats in AvatarResult.Provenance and, with OUTPUT_FILE, in a
+// sidecar file named like the avatar with ".provenance.json" appended. Generators and CachingFS keep
+// only the provenance embedded in PNGs.
+func WithProvenance(key ed25519.PrivateKey) func(a *Avatar) {
+	return func(a *Avatar) {
+		if len(key) != ed25519.PrivateKeySize {
+			a.err = fmt.Errorf("%w: expected an Ed25519 private key of %d bytes, got %d", ErrInvalidProvenance, ed25519.PrivateKeySize, len(key))
+			return
+		}
+		a.provenanceKey = key
+	}
+}
+
+// VerifyProvenance checks the signed provenance of the encoded avatar data against the public key of the
+// signing service and returns it. sidecar is the content of the provenance sidecar file, nil for PNGs
+// carrying their provenance. Returned errors wrap ErrNoProvenance or ErrInvalidProvenance.
+func VerifyProvenance(data, sidecar []byte, key ed25519.PublicKey) (*Provenance, error) {
+	content := data
+	if sidecar == nil {
+		var ok bool
+		if content, sidecar, ok = extractProvenance(data); !ok {
+			return nil, ErrNoProvenance
+		}
+	}
+	var envelope provenanceEnvelope
+	if err := json.Unmarshal(sidecar, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProvenance, err)
+	}
+	if len(key) != ed25519.PublicKeySize || !ed25519.Verify(key, envelope.Payload, envelope.Signature) {
+		return nil, fmt.Errorf("%w: signature does not match the key", ErrInvalidProvenance)
+	}
+	var provenance Provenance
+	if err := json.Unmarshal(envelope.Payload, &provenance); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProvenance, err)
+	}
+	if sum := sha256.Sum256(content); provenance.ContentHash != hex.EncodeToString(sum[:]) {
+		return nil, fmt.Errorf("%w: content hash does not match the avatar", ErrInvalidProvenance)
+	}
+	return &provenance, nil
+}
+
+// encodeSigned returns the encoded avatar and, with WithProvenance, its signed provenance.
+// Returned errors wrap ErrEncode.
+func (av *Avatar) encodeSigned() (data, provenance []byte, err error) {
+	if data, err = av.encodeBytes(); err != nil || av.provenanceKey == nil {
+		return data, nil, err
+	}
+	if data, provenance, err = av.signProvenance(data); err != nil {
+		return nil, nil, av.wrapErr(ErrEncode, "sign provenance", err)
+	}
+	return data, provenance, nil
+}
+
+// signProvenance signs the provenance of the encoded avatar data and returns data, with the
+// provenance embedded for PNGs, and the signed provenance.
+func (av *Avatar) signProvenance(data []byte) ([]byte, []byte, error) {
+	contentHash := sha256.Sum256(data)
+	valueHash := sha256.Sum256([]byte(av.value))
+	payload, err := json.Marshal(Provenance{
+		ContentHash: hex.EncodeToString(contentHash[:]),
+		ValueHash:   hex.EncodeToString(valueHash[:]),
+		Style:       styleFingerprint(av.styleKey(), av.theme),
+		Format:      av.format.String(),
+		Version:     moduleVersion(),
+		Time:        time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	envelope, err := json.Marshal(provenanceEnvelope{Payload: payload, Signature: ed25519.Sign(av.provenanceKey, payload)})
+	if err != nil {
+		return nil, nil, err
+	}
+	if av.format != FORMAT_PNG {
+		return data, envelope, nil
+	}
+	// The chunk goes right before IEND, the last chunk of every PNG.
+	var signed bytes.Buffer
+	signed.Write(data[:len(data)-pngIENDLen])
+	if err := writeChunk(&signed, "tEXt", append([]byte(provenanceKeyword+"\x00"), envelope...)); err != nil {
+		return nil, nil, err
+	}
+	signed.Write(data[len(data)-pngIENDLen:])
+	return signed.Bytes(), envelope, nil
+}
+
+// extractProvenance returns the PNG data without its provenance chunk and the signed provenance the chunk holds.
+func extractProvenance(data []byte) (content, envelope []byte, ok bool) {
+	const signatureLen = 8
+	if len(data) < signatureLen || string(data[:signatureLen]) != "\x89PNG\r\n\x1a\n" {
+		return nil, nil, false
+	}
+	for offset := signatureLen; offset+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		end := offset + 8 + length + 4
+		if length < 0 || end > len(data) {
+			return nil, nil, false
+		}
+		body := data[offset+8 : offset+8+length]
+		if keyword, text, found := bytes.Cut(body, []byte{0}); found && string(data[offset+4:offset+8]) == "tEXt" &&
+			string(keyword) == provenanceKeyword {
+			content = append(append([]byte(nil), data[:offset]...), data[end:]...)
+			return content, text, true
+		}
+		offset = end
+	}
+	return nil, nil, false
+}
+
+// provenanceKeyID returns a style key part identifying the public half of key, so avatars signed with
+// different keys are cached separately. It is empty without a key, keeping the keys of unsigned avatars.
+func provenanceKeyID(key ed25519.PrivateKey) string {
+	if key == nil {
+		return ""
+	}
+	return "-provenance" + hex.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+// writeProvenanceSidecar writes the signed provenance of the avatar saved at path beside it.
+func writeProvenanceSidecar(path string, envelope []byte) error {
+	return os.WriteFile(path+provenanceExtension, envelope, 0644)
+}
+
+// moduleVersion returns the version of this module in the running binary, "(devel)" if unknown.
+var moduleVersion = sync.OnceValue(func() string {
+	info, _ := debug.ReadBuildInfo()
+	var version string
+	switch {
+	case info == nil:
+	case info.Main.Path == modulePath:
+		version = info.Main.Version
+	default:
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				version = dep.Version
+				if dep.Replace != nil {
+					version = dep.Replace.Version
+				}
+			}
+		}
+	}
+	if version == "" {
+		return "(devel)"
+	}
+	return version
+})
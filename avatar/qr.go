@@ -0,0 +1,59 @@
+package avatar
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+)
+
+// qrQuietZone is the light border around QR codes in modules, as required by the specification.
+const qrQuietZone = 4
+
+// GenerateQRCode encodes content, e.g. a pairing or profile link, into a QR code with the avatar for
+// value in its center, so users can check that they scan the code of the expected account or device.
+// The code uses error correction level H, which restores the modules covered by the avatar, and is
+// drawn in black on white with a quiet zone, as the output dimension allows in whole pixels per module.
+// The options apply to the avatar. ErrQRContentTooLong is returned for content of more than 1273 bytes.
+func GenerateQRCode(value, content string, opts ...CreateOption) (*AvatarResult, error) {
+	code := New(value, opts...)
+	if err := code.validate(); err != nil {
+		return nil, err
+	}
+	qr, err := encodeQR([]byte(content))
+	if err != nil {
+		return nil, code.wrapErr(ErrInvalidOption, "encode qr code", err)
+	}
+	// The avatar covers a square of about a tenth of the modules around the center, which leaves every
+	// block of codewords spare error correction for smudges. Half a module of the square stays light.
+	center, radius := qr.size/2, max(qr.size/10, 2)
+	size := code.outputDimension()
+	module := size / (qr.size + 2*qrQuietZone)
+	iconSize := 2 * radius * module
+	if iconSize < int(code.pixelPattern) {
+		return nil, code.wrapErr(ErrInvalidOption, "check dimension",
+			fmt.Errorf("%w: %dpx is too small for a %dx%d QR code with the avatar", ErrInvalidDimension, size, qr.size, qr.size))
+	}
+	icon, err := code.renderMember(value, iconSize)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (size - qr.size*module) / 2
+	code.image = image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(code.image, code.image.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for y := 0; y < qr.size; y++ {
+		for x := 0; x < qr.size; x++ {
+			covered := abs(x-center) <= radius && abs(y-center) <= radius
+			if qr.modules[y][x] && !covered {
+				r := image.Rect(offset+x*module, offset+y*module, offset+(x+1)*module, offset+(y+1)*module)
+				draw.Draw(code.image, r, image.NewUniform(color.Black), image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	at := offset + (center-radius)*module + module/2
+	draw.Draw(code.image, image.Rect(at, at, at+iconSize, at+iconSize), icon.image, image.Point{}, draw.Over)
+	return code.output()
+}
@@ -0,0 +1,434 @@
+package avatar
+
+import "fmt"
+
+// Bounds of QR code versions. A version v code is 17+4v modules wide.
+const (
+	minQRVersion = 1
+	maxQRVersion = 40
+)
+
+// Error correction of QR codes at level H, which restores up to 30% of damaged codewords and so
+// tolerates the avatar covering the center. Indexed by version.
+var (
+	qrECCodewordsPerBlock = [maxQRVersion + 1]int{0,
+		17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28,
+		30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30}
+	qrECBlocks = [maxQRVersion + 1]int{0,
+		1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25,
+		25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81}
+)
+
+// qrFormatLevelH are the error correction bits of level H in the format information.
+const qrFormatLevelH = 2
+
+// qrCode is an encoded QR code with modules[y][x] set for dark modules.
+type qrCode struct {
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// encodeQR encodes data in byte mode at error correction level H into the smallest QR code it fits.
+func encodeQR(data []byte) (*qrCode, error) {
+	version := minQRVersion
+	for ; ; version++ {
+		if version > maxQRVersion {
+			return nil, fmt.Errorf("%w: %d bytes", ErrQRContentTooLong, len(data))
+		}
+		if qrDataBits(version, len(data)) <= qrDataCodewords(version)*8 {
+			break
+		}
+	}
+
+	var bits qrBitBuffer
+	bits.append(0b0100, 4)
+	bits.append(len(data), qrCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := qrDataCodewords(version) * 8
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, -len(bits)&7)
+	for pad := 0xec; len(bits) < capacity; pad ^= 0xec ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	codewords := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			codewords[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+
+	qr := newQRCode(version)
+	qr.drawCodewords(qrInterleave(version, codewords))
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		qr.applyMask(mask)
+		qr.drawFormatBits(mask)
+		if penalty := qr.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		qr.applyMask(mask)
+	}
+	qr.applyMask(best)
+	qr.drawFormatBits(best)
+	return qr, nil
+}
+
+// qrCountBits returns the width of the character count of byte mode segments.
+func qrCountBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// qrDataBits returns the number of bits of a byte mode segment of n bytes.
+func qrDataBits(version, n int) int {
+	return 4 + qrCountBits(version) + 8*n
+}
+
+// qrRawModules returns the number of modules of a version available for codewords, which are all
+// modules except the finder, timing and alignment patterns and the format and version information.
+func qrRawModules(version int) int {
+	n := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		n -= (25*align-10)*align - 55
+		if version >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+// qrDataCodewords returns the number of data codewords of a version at level H.
+func qrDataCodewords(version int) int {
+	return qrRawModules(version)/8 - qrECCodewordsPerBlock[version]*qrECBlocks[version]
+}
+
+// qrInterleave splits the data codewords into blocks, appends the error correction codewords to
+// each block and interleaves the blocks. Blocks that are one codeword shorter come first.
+func qrInterleave(version int, data []byte) []byte {
+	blocks, ecLen := qrECBlocks[version], qrECCodewordsPerBlock[version]
+	raw := qrRawModules(version) / 8
+	short, shortLen := blocks-raw%blocks, raw/blocks
+	divisor := reedSolomonDivisor(ecLen)
+
+	parts := make([][]byte, blocks)
+	for i, k := 0, 0; i < blocks; i++ {
+		n := shortLen - ecLen
+		if i >= short {
+			n++
+		}
+		block := make([]byte, 0, shortLen+1)
+		block = append(block, data[k:k+n]...)
+		k += n
+		ec := reedSolomonRemainder(block, divisor)
+		if i < short {
+			// A placeholder keeps the error correction codewords of all blocks aligned; it is skipped below.
+			block = append(block, 0)
+		}
+		parts[i] = append(block, ec...)
+	}
+
+	result := make([]byte, 0, raw)
+	for i := 0; i <= shortLen; i++ {
+		for j, block := range parts {
+			if i != shortLen-ecLen || j >= short {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// reedSolomonDivisor returns the generator polynomial of degree n, highest coefficient first and
+// without the leading 1.
+func reedSolomonDivisor(n int) []byte {
+	divisor := make([]byte, n)
+	divisor[n-1] = 1
+	root := byte(1)
+	for i := 0; i < n; i++ {
+		for j := range divisor {
+			divisor[j] = gfMultiply(divisor[j], root)
+			if j+1 < n {
+				divisor[j] ^= divisor[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return divisor
+}
+
+// reedSolomonRemainder returns the error correction codewords of data.
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	remainder := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ remainder[0]
+		copy(remainder, remainder[1:])
+		remainder[len(remainder)-1] = 0
+		for i, d := range divisor {
+			remainder[i] ^= gfMultiply(d, factor)
+		}
+	}
+	return remainder
+}
+
+// gfMultiply multiplies in GF(2^8) modulo the QR code polynomial x^8+x^4+x^3+x^2+1.
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11d
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// qrBitBuffer collects the bits of the data codewords, most significant first.
+type qrBitBuffer []bool
+
+func (b *qrBitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 == 1)
+	}
+}
+
+// newQRCode returns a QR code of the version with its function patterns drawn.
+func newQRCode(version int) *qrCode {
+	size := 17 + 4*version
+	qr := &qrCode{size: size, modules: make([][]bool, size), function: make([][]bool, size)}
+	for y := range qr.modules {
+		qr.modules[y], qr.function[y] = make([]bool, size), make([]bool, size)
+	}
+
+	for i := 0; i < size; i++ {
+		qr.setFunction(6, i, i%2 == 0)
+		qr.setFunction(i, 6, i%2 == 0)
+	}
+	for _, c := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := c[0]+dx, c[1]+dy
+				if x >= 0 && x < size && y >= 0 && y < size {
+					d := max(abs(dx), abs(dy))
+					qr.setFunction(x, y, d != 2 && d != 4)
+				}
+			}
+		}
+	}
+	positions := qrAlignmentPositions(version)
+	for i, y := range positions {
+		for j, x := range positions {
+			// Alignment patterns next to the finder patterns are left out.
+			if i == 0 && j == 0 || i == 0 && j == len(positions)-1 || i == len(positions)-1 && j == 0 {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					qr.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+	// The format information is reserved before the codewords are drawn and set per mask.
+	qr.drawFormatBits(0)
+	qr.drawVersion(version)
+	return qr
+}
+
+// qrAlignmentPositions returns the centers of the alignment patterns on both axes.
+func qrAlignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	n := version/7 + 2
+	step := (version*4 + n*2 + 1) / (n*2 - 2) * 2
+	if version == 32 {
+		step = 26
+	}
+	positions := make([]int, n)
+	positions[0] = 6
+	for i, pos := n-1, 17+4*version-7; i >= 1; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+func (qr *qrCode) setFunction(x, y int, dark bool) {
+	qr.modules[y][x] = dark
+	qr.function[y][x] = true
+}
+
+// drawFormatBits draws both copies of the format information for level H and the mask.
+func (qr *qrCode) drawFormatBits(mask int) {
+	data := qrFormatLevelH<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		qr.setFunction(8, i, bit(i))
+	}
+	qr.setFunction(8, 7, bit(6))
+	qr.setFunction(8, 8, bit(7))
+	qr.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		qr.setFunction(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		qr.setFunction(qr.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		qr.setFunction(8, qr.size-15+i, bit(i))
+	}
+	qr.setFunction(8, qr.size-8, true)
+}
+
+// drawVersion draws both copies of the version information of versions 7 and up.
+func (qr *qrCode) drawVersion(version int) {
+	if version < 7 {
+		return
+	}
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1f25
+	}
+	bits := version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := bits>>i&1 == 1
+		a, b := qr.size-11+i%3, i/3
+		qr.setFunction(a, b, dark)
+		qr.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords draws the codewords in the zigzag of two module wide columns from the bottom right.
+func (qr *qrCode) drawCodewords(data []byte) {
+	i := 0
+	for right := qr.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			// The vertical timing pattern is skipped.
+			right = 5
+		}
+		for vert := 0; vert < qr.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = qr.size - 1 - vert
+				}
+				if !qr.function[y][x] && i < len(data)*8 {
+					qr.modules[y][x] = data[i>>3]>>(7-i&7)&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the codeword modules selected by the mask. Applying a mask twice undoes it.
+func (qr *qrCode) applyMask(mask int) {
+	for y := 0; y < qr.size; y++ {
+		for x := 0; x < qr.size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			default:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !qr.function[y][x] {
+				qr.modules[y][x] = !qr.modules[y][x]
+			}
+		}
+	}
+}
+
+// qrFinderLike are the module sequences that look like finder patterns, dark modules set.
+var qrFinderLike = [2][11]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// penalty scores how hard the code is to scan with the rules of the QR code specification, which
+// penalize runs of equal modules, blocks of equal modules, finder-like sequences and unbalanced
+// dark and light modules. The mask with the lowest penalty is used.
+func (qr *qrCode) penalty() int {
+	at := func(x, y int, transpose bool) bool {
+		if transpose {
+			return qr.modules[x][y]
+		}
+		return qr.modules[y][x]
+	}
+
+	penalty := 0
+	for _, transpose := range []bool{false, true} {
+		for y := 0; y < qr.size; y++ {
+			run := 1
+			for x := 1; x <= qr.size; x++ {
+				if x < qr.size && at(x, y, transpose) == at(x-1, y, transpose) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += run - 2
+				}
+				run = 1
+			}
+			for x := 0; x+11 <= qr.size; x++ {
+				for _, pattern := range qrFinderLike {
+					match := true
+					for i, dark := range pattern {
+						if at(x+i, y, transpose) != dark {
+							match = false
+							break
+						}
+					}
+					if match {
+						penalty += 40
+					}
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := 0; y < qr.size; y++ {
+		for x := 0; x < qr.size; x++ {
+			if qr.modules[y][x] {
+				dark++
+			}
+			if x > 0 && y > 0 {
+				c := qr.modules[y][x]
+				if c == qr.modules[y][x-1] && c == qr.modules[y-1][x] && c == qr.modules[y-1][x-1] {
+					penalty += 3
+				}
+			}
+		}
+	}
+	total := qr.size * qr.size
+	return penalty + ((abs(dark*20-total*10)+total-1)/total-1)*10
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,182 @@
+package avatar
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithDiskQuota limits the avatar files in the directory of a CachingFS, or in the output directory
+// with OUTPUT_FILE, to maxBytes bytes and maxFiles files, zero leaving either unlimited. After a file
+// is saved the least recently used avatar files are removed until the directory is within the quota
+// again, so the cache cannot fill a production volume. Files are used when they are saved or opened
+// through a CachingFS; files not used since the process started count as used when they were saved.
+// The directory is scanned once, when the quota is first enforced on it, and then tracked in memory,
+// so files written to it by other processes count only after a restart.
+// Only files with the extension of a registered format count, but the directory should hold avatars
+// only, as they are removed without regard to aliases pointing to them. Pass the same option to every
+// avatar saved to a directory, e.g. to NewGenerator, to share the usage records between them.
+func WithDiskQuota(maxBytes int64, maxFiles int) func(a *Avatar) {
+	q := &diskQuota{maxBytes: maxBytes, maxFiles: maxFiles, dirs: make(map[string]*quotaDir)}
+	return func(a *Avatar) {
+		if maxBytes < 0 || maxFiles < 0 || maxBytes == 0 && maxFiles == 0 {
+			a.err = fmt.Errorf("%w: %d bytes, %d files", ErrInvalidQuota, maxBytes, maxFiles)
+			return
+		}
+		a.quota = q
+	}
+}
+
+// diskQuota limits the size of a directory of avatar files, evicting the least recently used.
+type diskQuota struct {
+	maxBytes int64
+	maxFiles int
+	mu       sync.Mutex
+	// dirs holds the usage of every directory the quota was enforced on, by cleaned path.
+	dirs map[string]*quotaDir
+}
+
+// quotaDir is the usage of a directory, scanned once when the quota is first enforced on it and
+// kept up to date by the saves, opens and removals of this process.
+type quotaDir struct {
+	// files holds the avatar files from most to least recently used, byPath their elements.
+	files  *list.List
+	byPath map[string]*list.Element
+	total  int64
+}
+
+// quotaFile is an avatar file counted against a quota.
+type quotaFile struct {
+	path string
+	size int64
+}
+
+// touch records that the file at path was used.
+func (q *diskQuota) touch(path string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if d := q.dirs[filepath.Dir(path)]; d != nil {
+		if e, ok := d.byPath[path]; ok {
+			d.files.MoveToFront(e)
+		}
+	}
+}
+
+// forget stops counting the file at path, which was removed.
+func (q *diskQuota) forget(path string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if d := q.dirs[filepath.Dir(path)]; d != nil {
+		if e, ok := d.byPath[path]; ok {
+			d.remove(e)
+		}
+	}
+}
+
+// enforce records the file at keep, which was just saved to dir, as used and removes the least
+// recently used avatar files of dir until it is within the quota, keeping the file at keep.
+func (q *diskQuota) enforce(dir, keep string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	d, err := q.dir(dir)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(keep)
+	if err != nil {
+		return err
+	}
+	if e, ok := d.byPath[keep]; ok {
+		d.remove(e)
+	}
+	d.add(keep, info.Size())
+
+	for e := d.files.Back(); e != nil && q.exceeded(d); {
+		prev := e.Prev()
+		if f := e.Value.(*quotaFile); f.path != keep {
+			if err := os.Remove(f.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			if err := removeSidecars(f.path); err != nil {
+				return err
+			}
+			d.remove(e)
+		}
+		e = prev
+	}
+	return nil
+}
+
+// exceeded reports whether the files of d exceed the quota.
+func (q *diskQuota) exceeded(d *quotaDir) bool {
+	return q.maxBytes != 0 && d.total > q.maxBytes || q.maxFiles != 0 && d.files.Len() > q.maxFiles
+}
+
+// dir returns the usage of dir, scanning it if the quota was not enforced on it before.
+// Files not used by this process count as used when they were last modified.
+func (q *diskQuota) dir(dir string) (*quotaDir, error) {
+	dir = filepath.Clean(dir)
+	if d, ok := q.dirs[dir]; ok {
+		return d, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type scanned struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []scanned
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") || !isAvatarExtension(filepath.Ext(entry.Name())) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, scanned{filepath.Join(dir, entry.Name()), info.Size(), info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	d := &quotaDir{files: list.New(), byPath: make(map[string]*list.Element, len(files))}
+	for _, f := range files {
+		d.add(f.path, f.size)
+	}
+	q.dirs[dir] = d
+	return d, nil
+}
+
+// add counts the file at path as the most recently used.
+func (d *quotaDir) add(path string, size int64) {
+	d.byPath[path] = d.files.PushFront(&quotaFile{path, size})
+	d.total += size
+}
+
+// remove stops counting the file of e.
+func (d *quotaDir) remove(e *list.Element) {
+	f := d.files.Remove(e).(*quotaFile)
+	delete(d.byPath, f.path)
+	d.total -= f.size
+}
+
+// isAvatarExtension reports whether ext is the file extension of a registered format.
+func isAvatarExtension(ext string) bool {
+	encoderMu.RLock()
+	defer encoderMu.RUnlock()
+	for _, e := range encoders {
+		if e.extension == ext {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package avatar
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskQuota(t *testing.T) {
+	dir := t.TempDir()
+	// A file saved by an earlier process is the least recently used.
+	old := filepath.Join(dir, "old.png")
+	if err := os.WriteFile(old, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(old, past, past)
+
+	cfs := NewCachingFS(dir, WithDiskQuota(0, 3))
+	open := func(name string) {
+		t.Helper()
+		f, err := cfs.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	open("a.png")
+	open("b.png")
+	open("c.png")
+	open("a.png")
+	open("d.png")
+
+	for name, want := range map[string]bool{"old.png": false, "a.png": true, "b.png": false, "c.png": true, "d.png": true} {
+		if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != want {
+			t.Errorf("%s exists: got %t, want %t", name, err == nil, want)
+		}
+	}
+	d := cfs.quota.dirs[filepath.Clean(dir)]
+	var total int64
+	for _, name := range []string{"a.png", "c.png", "d.png"} {
+		info, _ := os.Stat(filepath.Join(dir, name))
+		total += info.Size()
+	}
+	if d.files.Len() != 3 || d.total != total {
+		t.Errorf("tracked %d files of %d bytes, want 3 files of %d bytes", d.files.Len(), d.total, total)
+	}
+}
@@ -0,0 +1,50 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// Saturation and lightness of the colors assigned by AssignDistinctColors.
+const (
+	rosterSaturation = 0.65
+	rosterLightness  = 0.50
+)
+
+// AssignDistinctColors assigns fill colors to a roster of values, e.g. the members of a team,
+// whose hues are spread evenly around the color wheel so that no two members look alike.
+// Every value keeps the position on the wheel of the hue derived from its hash, relative to the
+// other members, so colors only change when the roster does. Pass the colors to WithFillColor.
+func AssignDistinctColors(values []string) map[string]color.Color {
+	members := append([]string(nil), values...)
+	sort.Strings(members)
+	hues := make(map[string]float64, len(members))
+	unique := members[:0]
+	for _, member := range members {
+		if _, ok := hues[member]; ok {
+			continue
+		}
+		hues[member], _, _ = toHSL(New(member).color(sha256.Sum256([]byte(member))))
+		unique = append(unique, member)
+	}
+	sort.SliceStable(unique, func(i, j int) bool { return hues[unique[i]] < hues[unique[j]] })
+
+	// Rotate the evenly spaced hues to the circular mean of the members' displacements,
+	// keeping every hue as close as possible to the one derived from its hash.
+	step := 360 / float64(len(unique))
+	var sin, cos float64
+	for i, member := range unique {
+		shift := (hues[member] - float64(i)*step) * math.Pi / 180
+		sin, cos = sin+math.Sin(shift), cos+math.Cos(shift)
+	}
+	offset := math.Atan2(sin, cos) * 180 / math.Pi
+
+	colors := make(map[string]color.Color, len(unique))
+	for i, member := range unique {
+		hue := math.Mod(offset+float64(i)*step+360, 360)
+		colors[member] = fromHSL(hue, rosterSaturation, rosterLightness)
+	}
+	return colors
+}
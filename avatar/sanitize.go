@@ -0,0 +1,76 @@
+package avatar
+
+import "unicode"
+
+// WithValueSanitization applies SanitizeValue to the value, and to the members of group avatars,
+// before it is hashed, so visually identical values such as usernames get identical avatars.
+func WithValueSanitization() func(a *Avatar) {
+	return func(a *Avatar) {
+		a.sanitizeValue = true
+		a.value = SanitizeValue(a.value)
+	}
+}
+
+// SanitizeValue returns value with invisible characters removed and characters that are commonly
+// confused with Latin letters or digits replaced by them: zero-width and bidirectional formatting
+// characters, soft hyphens and variation selectors are removed, fullwidth forms, Unicode spaces and
+// Cyrillic and Greek look-alikes replaced, and Latin letters followed by a combining accent composed
+// into one character. The mapping is fixed, as changing it would change the avatars of existing
+// values, so it is deliberately smaller than full Unicode normalization and confusable detection.
+func SanitizeValue(value string) string {
+	sanitized := make([]rune, 0, len(value))
+	for _, r := range value {
+		switch {
+		case unicode.Is(unicode.Cf, r) || unicode.Is(unicode.Variation_Selector, r) || r == '͏':
+			continue
+		case r >= '！' && r <= '～':
+			r -= 0xfee0
+		case unicode.Is(unicode.Zs, r):
+			r = ' '
+		}
+		if latin, ok := homoglyphs[r]; ok {
+			r = latin
+		}
+		if n := len(sanitized); n > 0 {
+			if composed, ok := composeLatin1[[2]rune{sanitized[n-1], r}]; ok {
+				sanitized[n-1] = composed
+				continue
+			}
+		}
+		sanitized = append(sanitized, r)
+	}
+	return string(sanitized)
+}
+
+// homoglyphs maps Cyrillic and Greek letters to the Latin letters they are indistinguishable from
+// in common fonts.
+var homoglyphs = map[rune]rune{
+	'а': 'a', 'в': 'B', 'е': 'e', 'к': 'k', 'м': 'M', 'н': 'H', 'о': 'o', 'р': 'p', 'с': 'c',
+	'т': 'T', 'у': 'y', 'х': 'x', 'і': 'i', 'ј': 'j', 'ѕ': 's', 'ԁ': 'd', 'ү': 'y', 'һ': 'h',
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O', 'Р': 'P', 'С': 'C',
+	'Т': 'T', 'У': 'Y', 'Х': 'X', 'І': 'I', 'Ј': 'J', 'Ѕ': 'S',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N',
+	'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X', 'ο': 'o', 'ν': 'v',
+}
+
+// composeLatin1 maps a Latin letter and a following combining accent to the precomposed letter of
+// the Latin-1 Supplement block.
+var composeLatin1 = func() map[[2]rune]rune {
+	pairs := map[rune]string{
+		'̀': "AÀEÈIÌOÒUÙaàeèiìoòuù",
+		'́': "AÁEÉIÍOÓUÚYÝaáeéiíoóuúyý",
+		'̂': "AÂEÊIÎOÔUÛaâeêiîoôuû",
+		'̃': "AÃNÑOÕaãnñoõ",
+		'̈': "AÄEËIÏOÖUÜaäeëiïoöuüyÿ",
+		'̊': "AÅaå",
+		'̧': "CÇcç",
+	}
+	compose := make(map[[2]rune]rune)
+	for accent, letters := range pairs {
+		runes := []rune(letters)
+		for i := 0; i < len(runes); i += 2 {
+			compose[[2]rune{runes[i], accent}] = runes[i+1]
+		}
+	}
+	return compose
+}()
@@ -0,0 +1,32 @@
+package avatar
+
+import "image"
+
+// scaleCells scales src into the area of dst with nearest neighbor sampling, producing the same
+// pixels as draw.NearestNeighbor with draw.Src. Every row of cells is sampled once into a scanline
+// that is copied into the Pix rows it covers, which is an order of magnitude faster than setting
+// every pixel for large dimensions.
+func scaleCells(dst *image.RGBA, area image.Rectangle, src *image.RGBA) {
+	area = area.Intersect(dst.Bounds())
+	sb := src.Bounds()
+	if area.Empty() || sb.Empty() {
+		return
+	}
+	sw, sh := uint64(sb.Dx()), uint64(sb.Dy())
+	dw2, dh2 := 2*uint64(area.Dx()), 2*uint64(area.Dy())
+	scanline := make([]byte, 4*area.Dx())
+	sampled := -1
+	for dy := 0; dy < area.Dy(); dy++ {
+		sy := int((2*uint64(dy) + 1) * sh / dh2)
+		if sy != sampled {
+			for dx := 0; dx < area.Dx(); dx++ {
+				sx := int((2*uint64(dx) + 1) * sw / dw2)
+				i := src.PixOffset(sb.Min.X+sx, sb.Min.Y+sy)
+				copy(scanline[4*dx:4*dx+4], src.Pix[i:i+4])
+			}
+			sampled = sy
+		}
+		i := dst.PixOffset(area.Min.X, area.Min.Y+dy)
+		copy(dst.Pix[i:i+len(scanline)], scanline)
+	}
+}
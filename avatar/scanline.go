@@ -0,0 +1,171 @@
+package avatar
+
+import (
+	"bufio"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+// streamingThreshold is the number of pixels from which GenerateBanner encodes PNGs row by row
+// instead of rendering the whole image first, 16 megapixels or 64 MiB of RGBA.
+const streamingThreshold = 1 << 24
+
+// idatSize is the size of the IDAT chunks written by encodeScanlines.
+const idatSize = 1 << 16
+
+// scanlines is an image rendered one row at a time, so it can be encoded without holding all its pixels.
+type scanlines struct {
+	width, height int
+	// row writes the premultiplied RGBA pixels of row y into dst.
+	row func(y int, dst []byte)
+}
+
+// streamsPNG reports whether the avatar encodes a width by height composite as a stream of rows.
+// Byte budgets, placeholders, raw output and registered PNG encoders need the whole image.
+func (av *Avatar) streamsPNG(width, height int) bool {
+	if width*height < streamingThreshold || av.format != FORMAT_PNG || av.outputType == OUTPUT_RAW {
+		return false
+	}
+	if e, _ := lookupEncoder(FORMAT_PNG); e.encode != nil {
+		return false
+	}
+	return av.maxBytes == 0 && !av.blurHash && av.lqipSize == 0
+}
+
+// encodeScanlines writes img to w as a non-interlaced 8 bit RGBA PNG, rendering, filtering and
+// compressing one row at a time. It embeds the density in dots per inch unless it is 0.
+func encodeScanlines(w io.Writer, img *scanlines, density float64) error {
+	if _, err := io.WriteString(w, "\x89PNG\r\n\x1a\n"); err != nil {
+		return err
+	}
+	var header [13]byte
+	binary.BigEndian.PutUint32(header[0:], uint32(img.width))
+	binary.BigEndian.PutUint32(header[4:], uint32(img.height))
+	header[8], header[9] = 8, 6 // 8 bit RGBA
+	if err := writeChunk(w, "IHDR", header[:]); err != nil {
+		return err
+	}
+	if density != 0 {
+		if _, err := w.Write(physChunk(density)); err != nil {
+			return err
+		}
+	}
+
+	idat := bufio.NewWriterSize(chunkWriter{w, "IDAT"}, idatSize)
+	zw := zlib.NewWriter(idat)
+	rowLen := 4 * img.width
+	pix := make([]byte, rowLen)
+	cur, prev := make([]byte, rowLen), make([]byte, rowLen)
+	var filtered [5][]byte
+	for i := range filtered {
+		filtered[i] = make([]byte, 1+rowLen)
+		filtered[i][0] = byte(i)
+	}
+	for y := 0; y < img.height; y++ {
+		img.row(y, pix)
+		unpremultiply(cur, pix)
+		if _, err := zw.Write(filterRow(&filtered, cur, prev)); err != nil {
+			return err
+		}
+		cur, prev = prev, cur
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := idat.Flush(); err != nil {
+		return err
+	}
+	return writeChunk(w, "IEND", nil)
+}
+
+// unpremultiply converts the premultiplied RGBA pixels of src to the straight alpha PNG stores in dst.
+func unpremultiply(dst, src []byte) {
+	for i := 0; i < len(src); i += 4 {
+		switch a := uint32(src[i+3]) * 0x101; a {
+		case 0xffff:
+			copy(dst[i:i+4], src[i:i+4])
+		case 0:
+			dst[i], dst[i+1], dst[i+2], dst[i+3] = 0, 0, 0, 0
+		default:
+			// Same rounding as color.NRGBAModel, which image/png converts with.
+			dst[i] = uint8(uint32(src[i]) * 0x101 * 0xffff / a >> 8)
+			dst[i+1] = uint8(uint32(src[i+1]) * 0x101 * 0xffff / a >> 8)
+			dst[i+2] = uint8(uint32(src[i+2]) * 0x101 * 0xffff / a >> 8)
+			dst[i+3] = src[i+3]
+		}
+	}
+}
+
+// filterRow applies the five PNG filters to cur and returns the filtered row with the smallest sum of
+// absolute differences, the heuristic of image/png. Each filtered row starts with its filter type.
+func filterRow(filtered *[5][]byte, cur, prev []byte) []byte {
+	none, sub, up, avg, paeth := filtered[0][1:], filtered[1][1:], filtered[2][1:], filtered[3][1:], filtered[4][1:]
+	copy(none, cur)
+	for i := range cur {
+		var left, upLeft byte
+		if i >= 4 {
+			left, upLeft = cur[i-4], prev[i-4]
+		}
+		sub[i] = cur[i] - left
+		up[i] = cur[i] - prev[i]
+		avg[i] = cur[i] - byte((int(left)+int(prev[i]))/2)
+		paeth[i] = cur[i] - paethPredictor(left, prev[i], upLeft)
+	}
+	best, bestSum := 0, -1
+	for f := range filtered {
+		sum := 0
+		for _, b := range filtered[f][1:] {
+			sum += abs(int(int8(b)))
+		}
+		if bestSum < 0 || sum < bestSum {
+			best, bestSum = f, sum
+		}
+	}
+	return filtered[best]
+}
+
+// paethPredictor returns whichever of a (left), b (up) and c (upper left) is closest to a + b - c.
+func paethPredictor(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+// chunkWriter writes every Write to w as a PNG chunk of the given type.
+type chunkWriter struct {
+	w         io.Writer
+	chunkType string
+}
+
+func (c chunkWriter) Write(data []byte) (int, error) {
+	if err := writeChunk(c.w, c.chunkType, data); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// writeChunk writes a PNG chunk of the given type holding data to w.
+func writeChunk(w io.Writer, chunkType string, data []byte) error {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[0:], uint32(len(data)))
+	copy(header[4:], chunkType)
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	var footer [4]byte
+	binary.BigEndian.PutUint32(footer[:], crc.Sum32())
+	for _, b := range [][]byte{header[:], data, footer[:]} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
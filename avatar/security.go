@@ -0,0 +1,16 @@
+package avatar
+
+// WithSecurityProfile configures avatars for the visual verification of security fingerprints such
+// as key or certificate hashes. Mirror symmetric patterns repeat half of their cells, which halves
+// the bits an attacker has to match and makes near collisions easier to craft. The profile instead
+// draws every cell of a PIXEL_PATTERN_12 grid independently with ALGORITHM_3, from a PRNG_CHACHA8
+// stream seeded with the whole value hash, so the pattern shows 144 bits. Options given after
+// WithSecurityProfile override parts of the profile.
+func WithSecurityProfile() func(a *Avatar) {
+	return func(a *Avatar) {
+		a.pixelPattern = PIXEL_PATTERN_12
+		a.algo = ALGORITHM_3
+		a.prng = PRNG_CHACHA8
+		a.algoParams = nil
+	}
+}
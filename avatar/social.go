@@ -0,0 +1,77 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+)
+
+// Dimensions of social cards in pixels, the size recommended for og:image.
+const (
+	socialCardWidth  = 1200
+	socialCardHeight = 630
+	socialCardMargin = 80
+)
+
+// Scales of the bitmap font on social cards.
+const (
+	maxSocialTitleScale    = 12
+	minSocialTitleScale    = 5
+	minSocialSubtitleScale = 3
+)
+
+// GenerateSocialCard composes a 1200x630 image for og:image and similar link preview tags, showing the
+// avatar for value on the left and the title above the subtitle on the right. The background is a
+// gradient of the brand color set by WithBrandColor, or of the hash derived gradient of
+// WithGradientBackground, with the text in black or white, whichever contrasts better. Texts that do
+// not fit are shortened with a trailing "...". The built-in font covers ASCII only: accented Latin
+// letters are drawn without their accents and other characters outside of ASCII as '?'. The options
+// apply to the avatar, whose dimension is fixed by the card.
+func GenerateSocialCard(value, title, subtitle string, opts ...CreateOption) (*AvatarResult, error) {
+	size := socialCardHeight - 2*socialCardMargin
+	card := New(value, opts...)
+	card.dimension, card.scaleFactor = uint(size), 1
+	if err := card.validate(); err != nil {
+		return nil, err
+	}
+	icon, err := card.renderMember(value, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var from, to color.RGBA
+	if card.brandColor != nil {
+		h, s, l := toHSL(card.brandColor)
+		from, to = fromHSL(h, s, l), fromHSL(h, s, l*0.7)
+	} else {
+		from, to = card.gradientStops(sha256.Sum256([]byte(value)))
+	}
+	foreground := color.Color(color.White)
+	if (luminance(from)+luminance(to))/2 > contrastLuminance {
+		foreground = color.Black
+	}
+	card.image = image.NewRGBA(image.Rect(0, 0, socialCardWidth, socialCardHeight))
+	fillGradient(card.image, from, to)
+	draw.Draw(card.image, image.Rect(socialCardMargin, socialCardMargin, socialCardMargin+size, socialCardMargin+size),
+		icon.image, image.Point{}, draw.Over)
+
+	x := 2*socialCardMargin + size
+	width := socialCardWidth - socialCardMargin - x
+	titleScale := max(min(fitTextScale(title, width, size/3), maxSocialTitleScale), minSocialTitleScale)
+	subtitleScale := max(titleScale/2, minSocialSubtitleScale)
+	title, subtitle = truncateText(title, width, titleScale), truncateText(subtitle, width, subtitleScale)
+	gap := textHeight(subtitleScale)
+	height := textHeight(titleScale)
+	if subtitle != "" {
+		height += gap + textHeight(subtitleScale)
+	}
+	y := (socialCardHeight - height) / 2
+	drawText(card.image, title, x, y, titleScale, foreground)
+	if subtitle != "" {
+		drawText(card.image, subtitle, x, y+textHeight(titleScale)+gap, subtitleScale, foreground)
+	}
+
+	return card.output()
+}
@@ -0,0 +1,123 @@
+package avatar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// LoadStyle reads JSON encoded Options from the style file at path.
+func LoadStyle(path string) (Options, error) {
+	var style Options
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return style, fmt.Errorf("load style: %w", err)
+	}
+	if err := json.Unmarshal(data, &style); err != nil {
+		return style, fmt.Errorf("parse style file %s: %w", path, err)
+	}
+	return style, nil
+}
+
+// StyleWatcher keeps a Generator in sync with a style file.
+// The file is reloaded when its modification time changes or the process receives SIGHUP,
+// and the current Generator is swapped atomically. Cached avatars are kept across reloads, within
+// the default limits of WithMemoryCacheLimit, so values rendered with an unchanged style are not regenerated.
+type StyleWatcher struct {
+	path     string
+	cache    *memoryCache
+	canvases *canvasPool
+	current  atomic.Pointer[Generator]
+	mu       sync.Mutex
+	modTime  time.Time
+	// OnError, when set, is called with errors encountered while reloading the style file.
+	// The previous Generator stays active when a reload fails.
+	OnError func(err error)
+}
+
+// WatchStyle loads the style file at path and keeps watching it until ctx is done.
+// The file is polled every interval; a zero interval disables polling and leaves SIGHUP as the only trigger.
+func WatchStyle(ctx context.Context, path string, interval time.Duration) (*StyleWatcher, error) {
+	w := &StyleWatcher{path: path, cache: newMemoryCache(defaultCacheEntries, defaultCacheBytes), canvases: newCanvasPool()}
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+	go w.watch(ctx, interval)
+	return w, nil
+}
+
+// Generator returns the Generator for the most recently loaded style.
+func (w *StyleWatcher) Generator() *Generator {
+	return w.current.Load()
+}
+
+// Reload reads the style file and swaps in a new Generator.
+func (w *StyleWatcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+	style, err := LoadStyle(w.path)
+	if err != nil {
+		return err
+	}
+	opts := style.CreateOptions()
+	if err := New("", opts...).validateOptions(); err != nil {
+		return err
+	}
+	w.modTime = info.ModTime()
+	w.current.Store(newGenerator(w.cache, w.canvases, opts))
+	return nil
+}
+
+func (w *StyleWatcher) watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			w.reportError(w.Reload())
+		case <-tick:
+			if w.changed() {
+				w.reportError(w.Reload())
+			}
+		}
+	}
+}
+
+// changed reports whether the style file was modified since it was last loaded.
+func (w *StyleWatcher) changed() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		w.reportError(err)
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !info.ModTime().Equal(w.modTime)
+}
+
+func (w *StyleWatcher) reportError(err error) {
+	if err != nil && w.OnError != nil {
+		w.OnError(err)
+	}
+}
@@ -0,0 +1,50 @@
+package avatar
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStyleWatcherReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "style.json")
+	if err := os.WriteFile(path, []byte(`{"pixel_pattern": 7}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := WatchStyle(ctx, path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := w.Generator()
+
+	// A file cannot hold the output directory.
+	blocker := filepath.Join(dir, "file")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	style := `{"pixel_pattern": 9, "output_dir": "` + filepath.ToSlash(filepath.Join(blocker, "avatars")) + `"}`
+	if err := os.WriteFile(path, []byte(style), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reload(); !errors.Is(err, ErrInvalidOutputDir) {
+		t.Errorf("got %v, want ErrInvalidOutputDir", err)
+	}
+	if w.Generator() != before {
+		t.Error("failed reload replaced the Generator")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"pixel_pattern": 9}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if w.Generator() == before {
+		t.Error("reload kept the previous Generator")
+	}
+}
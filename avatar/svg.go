@@ -0,0 +1,161 @@
+package avatar
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Names of the CSS custom properties of WithSVGVariables. Further cell colors, e.g. the companion
+// shade of WithTwoTone, are numbered from 2 like "--identicon-fg-2".
+const (
+	svgForegroundVariable = "--identicon-fg"
+	svgBackgroundVariable = "--identicon-bg"
+)
+
+// WithSVGVariables emits the colors of SVG output as the CSS custom properties --identicon-fg and
+// --identicon-bg, with the rendered colors as fallbacks, so a single SVG inlined into a page adapts
+// to page themes without regeneration:
+//
+//	@media (prefers-color-scheme: dark) { .avatar { --identicon-bg: #1e1e1e; } }
+//
+// Browsers do not apply page styles to SVGs loaded with <img>, which keep the fallback colors.
+func WithSVGVariables() func(a *Avatar) {
+	return func(a *Avatar) {
+		a.svgVariables = true
+	}
+}
+
+// defaultSVGTitle is the title of SVG output unless WithSVGTitle sets another one.
+const defaultSVGTitle = "Identicon"
+
+// WithSVGTitle sets the <title> and <desc> elements of SVG output, and the label of FORMAT_HTML tables,
+// which screen readers announce and accessibility audits require for images. An empty title keeps "Identicon" and an empty description
+// keeps the one of DescribePattern in the locale of WithLocale.
+func WithSVGTitle(title, desc string) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.svgTitle, a.svgDesc = title, desc
+	}
+}
+
+// WriteSVG renders the avatar and streams it to w as SVG, regardless of the format option.
+func (av *Avatar) WriteSVG(w io.Writer) error {
+	av, err := av.prepare()
+	if err != nil {
+		return err
+	}
+	if err := av.render(); err != nil {
+		return err
+	}
+	if err := av.writeSVG(w); err != nil {
+		return av.wrapErr(ErrWrite, "write svg", err)
+	}
+	return nil
+}
+
+// writeSVG writes the rendered pixel pattern as SVG, one path per cell color on the background.
+// The SVG scales without loss and is much smaller than a PNG of the same avatar, but it leaves out
+// the raster effects: masks, bevels, gradient backgrounds, overlays and decorations. Placeholder
+// photos are written as their radial gradients.
+func (av *Avatar) writeSVG(w io.Writer) error {
+	title, desc, err := av.labels()
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256([]byte(av.value))
+	size := av.outputDimension()
+	bw := bufio.NewWriter(w)
+	// Ids of inlined SVGs share the page, so they are made unique per value.
+	id := fmt.Sprintf("identicon-%x", hash[:4])
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges" role="img" aria-labelledby="%s-title %s-desc">`,
+		size, size, size, size, id, id)
+	fmt.Fprintf(bw, `<title id="%s-title">%s</title><desc id="%s-desc">%s</desc>`, id, html.EscapeString(title), id, html.EscapeString(desc))
+	if av.overrideImage != nil {
+		if err := av.writeOverrideSVG(bw); err != nil {
+			return err
+		}
+	} else if av.placeholderPhoto {
+		av.writePhotoSVG(bw, hash, id)
+	} else {
+		av.writePatternSVG(bw, hash)
+	}
+	bw.WriteString("</svg>")
+	return bw.Flush()
+}
+
+// writePatternSVG writes the background and the paths of the pixel pattern.
+func (av *Avatar) writePatternSVG(w io.Writer, hash [sha256.Size]byte) {
+	fill, background := av.patternColors(hash)
+	size, area := av.outputDimension(), av.patternRect()
+	cells := int(av.pixelPattern)
+
+	var colors []color.NRGBA
+	paths := make(map[color.NRGBA]*strings.Builder)
+	for y := 0; y < cells; y++ {
+		for x := 0; x < cells; x++ {
+			c := av.pattern.At(x, y)
+			if sameColor(c, background) {
+				continue
+			}
+			key := color.NRGBAModel.Convert(c).(color.NRGBA)
+			if paths[key] == nil {
+				paths[key] = new(strings.Builder)
+				// The fill color comes first, so it is the one named --identicon-fg.
+				if sameColor(c, fill) {
+					colors = append([]color.NRGBA{key}, colors...)
+				} else {
+					colors = append(colors, key)
+				}
+			}
+			fmt.Fprintf(paths[key], "M%d %dh1v1h-1z", x, y)
+		}
+	}
+
+	if bg := color.NRGBAModel.Convert(background).(color.NRGBA); bg.A > 0 {
+		fmt.Fprintf(w, `<rect width="%d" height="%d"%s/>`, size, size, av.svgFill(bg, svgBackgroundVariable))
+	}
+	scale := strconv.FormatFloat(float64(area.Dx())/float64(cells), 'g', -1, 64)
+	fmt.Fprintf(w, `<g transform="translate(%d %d) scale(%s)">`, area.Min.X, area.Min.Y, scale)
+	for i, c := range colors {
+		variable := svgForegroundVariable
+		if i > 0 {
+			variable += "-" + strconv.Itoa(i+1)
+		}
+		fmt.Fprintf(w, `<path%s d="%s"/>`, av.svgFill(c, variable), paths[c])
+	}
+	io.WriteString(w, "</g>")
+}
+
+// labels returns the title and description of the avatar for assistive technologies, see WithSVGTitle.
+func (av *Avatar) labels() (title, desc string, err error) {
+	title, desc = av.svgTitle, av.svgDesc
+	if title == "" {
+		title = defaultSVGTitle
+	}
+	// Placeholder photos have no pattern to describe.
+	if desc == "" && !av.placeholderPhoto {
+		// A clone keeps the rendered image, which DescribePattern would replace by the pattern.
+		if desc, err = av.Clone().DescribePattern(); err != nil {
+			return "", "", err
+		}
+	}
+	return title, desc, nil
+}
+
+// svgFill returns the fill attributes of c, as the CSS custom property variable if WithSVGVariables is set.
+func (av *Avatar) svgFill(c color.NRGBA, variable string) string {
+	fill := fmt.Sprintf(` fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if av.svgVariables {
+		fill = fmt.Sprintf(` style="fill:var(%s,#%02x%02x%02x)"`, variable, c.R, c.G, c.B)
+	}
+	if c.A < 0xff {
+		fill += ` fill-opacity="` + strconv.FormatFloat(float64(c.A)/0xff, 'f', 3, 64) + `"`
+	}
+	return fill
+}
@@ -0,0 +1,92 @@
+package avatar
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"io"
+)
+
+// writeHTMLTable writes the rendered pixel pattern as an HTML table of fixed size cells colored with
+// bgcolor attributes, for HTML emails in which neither images nor modern CSS render reliably. Cells
+// of one color in a row are merged with colspan, translucent colors are blended over the background
+// because email clients ignore alpha. Raster effects are left out as in SVG output.
+func (av *Avatar) writeHTMLTable(w io.Writer) error {
+	title, desc, err := av.labels()
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256([]byte(av.value))
+	_, background := av.patternColors(hash)
+	size, area := av.outputDimension(), av.patternRect()
+	cells := int(av.pixelPattern)
+	pattern := image.Image(av.pattern)
+	if av.overrideImage != nil {
+		pattern = av.overridePattern(cells)
+	} else if av.placeholderPhoto {
+		// Tables cannot show gradients, so placeholder photos are a single cell of their base color.
+		base, _ := av.photoBlobs(hash)
+		background, pattern, cells = base, image.NewUniform(base), 1
+	}
+	edge := func(i int) int { return i * area.Dx() / cells }
+
+	label := title
+	if desc != "" {
+		label += ": " + desc
+	}
+	bw := bufio.NewWriter(w)
+	bgcolor := ""
+	if bg := color.NRGBAModel.Convert(background).(color.NRGBA); bg.A > 0 {
+		bgcolor = fmt.Sprintf(` bgcolor="%s"`, opaqueHex(background, background))
+	}
+	// The inset of WithSafeArea becomes the cell padding of an outer table.
+	if inset := area.Min.X; inset > 0 {
+		fmt.Fprintf(bw, `<table role="presentation" width="%d" cellpadding="%d" cellspacing="0" border="0"%s><tr><td>`,
+			size, inset, bgcolor)
+	}
+	fmt.Fprintf(bw, `<table role="img" aria-label="%s" title="%s" width="%d" height="%d" cellpadding="0" cellspacing="0" border="0" style="border-collapse:collapse"%s>`,
+		html.EscapeString(label), html.EscapeString(title), area.Dx(), area.Dy(), bgcolor)
+	for y := 0; y < cells; y++ {
+		height := edge(y+1) - edge(y)
+		bw.WriteString("<tr>")
+		for x := 0; x < cells; {
+			c := pattern.At(x, y)
+			run := 1
+			for x+run < cells && sameColor(pattern.At(x+run, y), c) {
+				run++
+			}
+			width := edge(x+run) - edge(x)
+			fmt.Fprintf(bw, `<td width="%d" height="%d"`, width, height)
+			if run > 1 {
+				fmt.Fprintf(bw, ` colspan="%d"`, run)
+			}
+			if !sameColor(c, background) {
+				fmt.Fprintf(bw, ` bgcolor="%s"`, opaqueHex(c, background))
+			}
+			fmt.Fprintf(bw, ` style="width:%dpx;height:%dpx;font-size:0;line-height:0">&nbsp;</td>`, width, height)
+			x += run
+		}
+		bw.WriteString("</tr>")
+	}
+	bw.WriteString("</table>")
+	if area.Min.X > 0 {
+		bw.WriteString("</td></tr></table>")
+	}
+	return bw.Flush()
+}
+
+// opaqueHex returns c blended over the opaque parts of background in the #rrggbb notation.
+func opaqueHex(c, background color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	b := color.NRGBAModel.Convert(background).(color.NRGBA)
+	if b.A == 0 {
+		b = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	}
+	blend := func(fg, bg uint8) uint8 {
+		return uint8((int(fg)*int(n.A) + int(bg)*(0xff-int(n.A)) + 0x7f) / 0xff)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", blend(n.R, b.R), blend(n.G, b.G), blend(n.B, b.B))
+}
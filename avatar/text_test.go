@@ -0,0 +1,39 @@
+package avatar
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFontText(t *testing.T) {
+	for text, want := range map[string]string{
+		"octocat":                          "octocat",
+		"Zoë Ærø":                          "Zoe AEro",
+		"Straße Łódź":                      "Strasse Lodz",
+		"Jose\u0301":                       "Jose",
+		"\u201cHi\u201d \u2013 wait\u2026": "\"Hi\" - wait...",
+		"日本":                               "日本",
+	} {
+		if got := fontText(text); got != want {
+			t.Errorf("fontText(%q) = %q, want %q", text, got, want)
+		}
+	}
+	if got, want := textWidth("Straße", 1), textWidth("Strasse", 1); got != want {
+		t.Errorf("textWidth: got %d, want %d", got, want)
+	}
+}
+
+func TestDrawTextTransliterates(t *testing.T) {
+	draw := func(text string) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, textWidth(text, 1), textHeight(1)))
+		drawText(img, text, 0, 0, 1, color.Black)
+		return img
+	}
+	if !equalImages(draw("Zoë"), draw("Zoe")) {
+		t.Error(`"Zoë" is not drawn like "Zoe"`)
+	}
+	if !equalImages(draw("日"), draw("?")) {
+		t.Error(`"日" is not drawn like "?"`)
+	}
+}
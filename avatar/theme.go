@@ -0,0 +1,61 @@
+package avatar
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"golang.org/x/image/draw"
+)
+
+// Theme restyles avatars for a season or an event without changing their patterns.
+type Theme struct {
+	// Name identifies the theme. Avatars of the same value and theme name are identical,
+	// and Generators cache them per theme name.
+	Name string
+	// Palette replaces the palette of the avatar unless empty.
+	Palette []color.Color
+	// Overlay is scaled to the output dimension and drawn over the avatar unless nil,
+	// e.g. a transparent image of a party hat.
+	Overlay image.Image
+}
+
+// WithThemeProvider applies the theme returned for the current time when the avatar is first rendered,
+// e.g. a winter palette in December. The provider should return the zero Theme outside of events.
+// Brand and fill colors take precedence over theme palettes.
+func WithThemeProvider(provider func(time.Time) Theme) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.themeProvider = provider
+	}
+}
+
+// withTheme applies a theme resolved in advance, so the cache key and the rendered avatar agree.
+func withTheme(theme Theme) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.theme = &theme
+	}
+}
+
+// resolveTheme asks the theme provider for the current theme unless a theme was resolved before.
+func (av *Avatar) resolveTheme() {
+	if av.theme == nil && av.themeProvider != nil {
+		theme := av.themeProvider(time.Now())
+		av.theme = &theme
+	}
+}
+
+// activePalette returns the palette of the theme, or the configured one if the theme has none.
+func (av *Avatar) activePalette() []color.Color {
+	if av.theme != nil && len(av.theme.Palette) > 0 {
+		return av.theme.Palette
+	}
+	return av.palette
+}
+
+// drawOverlay draws the theme overlay over the scaled image.
+func (av *Avatar) drawOverlay() {
+	if av.theme == nil || av.theme.Overlay == nil {
+		return
+	}
+	draw.ApproxBiLinear.Scale(av.image, av.image.Bounds(), av.theme.Overlay, av.theme.Overlay.Bounds(), draw.Over, nil)
+}
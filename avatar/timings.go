@@ -0,0 +1,40 @@
+package avatar
+
+import (
+	"context"
+	"runtime/pprof"
+	"time"
+)
+
+// phaseLabel is the pprof label naming the generation phase a sample was taken in.
+const phaseLabel = "godenticon.phase"
+
+// Timings holds the time spent in each phase of generating an avatar. Avatars that are re-rendered,
+// e.g. at smaller dimensions to fit WithMaxBytes, add up the time of every attempt.
+type Timings struct {
+	// Hash is the time spent hashing the value.
+	Hash time.Duration
+	// Pattern is the time spent drawing the pixel pattern.
+	Pattern time.Duration
+	// Render is the time spent scaling the pattern and applying effects.
+	Render time.Duration
+	// Encode is the time spent encoding the image.
+	Encode time.Duration
+	// IO is the time spent saving the file with OUTPUT_FILE.
+	IO time.Duration
+}
+
+// phase runs fn with the pprof label of the named phase, on top of the labels of the context passed
+// to GenerateContext, and adds the time it took to d. CPU profiles then attribute the samples of
+// generations to their phases, e.g. with "go tool pprof -tagfocus godenticon.phase=encode".
+func (av *Avatar) phase(name string, d *time.Duration, fn func()) {
+	ctx := av.profileCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	start := time.Now()
+	pprof.Do(ctx, pprof.Labels(phaseLabel, name), func(context.Context) {
+		fn()
+	})
+	*d += time.Since(start)
+}
@@ -0,0 +1,232 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Trace records how the pixel pattern and the colors of an avatar are derived from its value, step
+// by step, so frontends in other languages can reimplement the derivation and compare against the Go
+// backend. Colors are given as #rrggbbaa with premultiplied alpha, the bytes the rendered image holds.
+type Trace struct {
+	Value string `json:"value"`
+	// SHA256 is the hash of the value, PatternHash and ColorHash the parts of it that WithEntropySplit
+	// keeps for the pattern and the colors, the whole hash without an entropy split.
+	SHA256       string       `json:"sha256"`
+	PatternHash  string       `json:"pattern_hash"`
+	ColorHash    string       `json:"color_hash"`
+	PixelPattern PixelPattern `json:"pixel_pattern"`
+	Algorithm    Algorithm    `json:"algorithm"`
+	PRNG         PRNG         `json:"prng"`
+	// Seed is the seed of the math/rand source with PRNG_MATH_RAND, the first four bytes of
+	// PatternHash read big endian. The other PRNGs are seeded with the whole PatternHash.
+	Seed uint32 `json:"seed"`
+	// Draws are the numbers taken from the random source in order.
+	Draws      []TraceDraw `json:"draws"`
+	Color      TraceColor  `json:"color"`
+	Background string      `json:"background"`
+	// Pattern holds the rows of the pixel pattern, "#" for filled and "." for empty cells.
+	Pattern []string `json:"pattern"`
+}
+
+// TraceDraw is a number taken from the random source and the cells set until the next one. The cells
+// of the last draw include those the algorithm sets after it, such as the mirrored half of the pattern.
+type TraceDraw struct {
+	// Method is "Int63" or "Uint64", the method of math/rand.Source64 that returned the number.
+	Method string `json:"method"`
+	// Value is the number in decimal, as JSON numbers lose precision above 2^53.
+	Value string      `json:"value"`
+	Cells []TraceCell `json:"cells,omitempty"`
+}
+
+// TraceCell is a cell the algorithm set after a draw.
+type TraceCell struct {
+	X      int  `json:"x"`
+	Y      int  `json:"y"`
+	Filled bool `json:"filled"`
+}
+
+// TraceColor records how the fill color is derived.
+type TraceColor struct {
+	// Source is the rule the color follows: "fill_color", "gradient", "brand", "palette", "oklch",
+	// "cielch" or "rgb".
+	Source string `json:"source"`
+	// Inputs are the numbers the rule derives the color from: the byte sums of the color hash for
+	// "rgb", hue, saturation or chroma and lightness for the others and the index into the palette.
+	Inputs map[string]float64 `json:"inputs,omitempty"`
+	// Stops are the colors of the background gradient with WithGradientBackground.
+	Stops []string `json:"stops,omitempty"`
+	// Derived is the color before WithLightnessRange and WithSaturationRange clamp it into Fill.
+	Derived string `json:"derived,omitempty"`
+	Fill    string `json:"fill"`
+}
+
+// Trace derives the pixel pattern and the colors of the avatar and returns every step of the derivation.
+func (av *Avatar) Trace() (*Trace, error) {
+	av, err := av.prepare()
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256([]byte(av.value))
+	patternHash, colorHash := av.entropySplit.patternHash(hash), av.entropySplit.colorHash(hash)
+	t := &Trace{
+		Value:        av.value,
+		SHA256:       hex.EncodeToString(hash[:]),
+		PatternHash:  hex.EncodeToString(patternHash[:]),
+		ColorHash:    hex.EncodeToString(colorHash[:]),
+		PixelPattern: av.pixelPattern,
+		Algorithm:    av.algo,
+		PRNG:         av.prng,
+		Seed:         binary.BigEndian.Uint32(patternHash[:]),
+	}
+
+	var recorder *traceSource
+	background, err := av.drawPatternFrom(hash, func(src rand.Source) rand.Source {
+		fill, _ := av.patternColors(hash)
+		recorder = &traceSource{src: src, img: av.image, fill: fill, trace: t, last: make([]byte, len(av.image.Pix))}
+		return recorder
+	})
+	if err != nil {
+		return nil, err
+	}
+	recorder.attribute()
+
+	t.Color = av.traceColor(hash)
+	t.Background = traceHex(background)
+	fill, _ := av.patternColors(hash)
+	for y := 0; y < int(av.pixelPattern); y++ {
+		var row strings.Builder
+		for x := 0; x < int(av.pixelPattern); x++ {
+			if sameColor(av.image.At(x, y), fill) {
+				row.WriteByte('#')
+			} else {
+				row.WriteByte('.')
+			}
+		}
+		t.Pattern = append(t.Pattern, row.String())
+	}
+	return t, nil
+}
+
+// traceColor returns the derivation of the fill color, following patternColors.
+func (av *Avatar) traceColor(hash [sha256.Size]byte) TraceColor {
+	fill, _ := av.patternColors(hash)
+	t := TraceColor{Fill: traceHex(fill)}
+	colors := av.entropySplit.colorHash(hash)
+	switch {
+	case av.gradientBackground:
+		from, to := av.gradientStops(hash)
+		t.Source = "gradient"
+		t.Inputs = map[string]float64{"hue": hashFraction(colors[0:4]) * 360, "hue_shift": 30 + hashFraction(colors[4:8])*60}
+		t.Stops = []string{traceHex(from), traceHex(to)}
+		return t
+	case av.fillColor != nil:
+		t.Source = "fill_color"
+		return t
+	case av.brandColor != nil:
+		h, _, _ := toHSL(av.brandColor)
+		t.Source = "brand"
+		t.Inputs = map[string]float64{
+			"hue":        h,
+			"saturation": brandMinSaturation + hashFraction(colors[16:20])*brandSaturationRange,
+			"lightness":  brandMinLightness + hashFraction(colors[20:24])*brandLightnessRange,
+		}
+	case len(av.activePalette()) > 0:
+		size := len(av.activePalette())
+		t.Source = "palette"
+		t.Inputs = map[string]float64{"index": float64(binary.BigEndian.Uint64(colors[24:32]) % uint64(size)), "size": float64(size)}
+	case av.colorSpace != COLOR_SPACE_RGB:
+		bands := defaultColorBands[av.colorSpace]
+		if av.colorBands != nil {
+			bands = *av.colorBands
+		}
+		t.Source = "oklch"
+		if av.colorSpace == COLOR_SPACE_CIELCH {
+			t.Source = "cielch"
+		}
+		// The chroma is lowered where the color falls outside the sRGB gamut.
+		t.Inputs = map[string]float64{
+			"hue":       hashFraction(colors[0:4]) * 360,
+			"lightness": bands.minLightness + hashFraction(colors[4:8])*(bands.maxLightness-bands.minLightness),
+			"chroma":    bands.minChroma + hashFraction(colors[8:12])*(bands.maxChroma-bands.minChroma),
+		}
+	default:
+		t.Source = "rgb"
+		t.Inputs = map[string]float64{
+			"red":   float64(byteSum(colors[0:8])),
+			"green": float64(byteSum(colors[8:16])),
+			"blue":  float64(byteSum(colors[16:24])),
+			"alpha": float64(byteSum(colors[24:32])),
+		}
+	}
+	t.Derived = traceHex(av.derivedColor(colors))
+	return t
+}
+
+// traceSource records the numbers taken from src and the cells of the pattern set after each of them.
+type traceSource struct {
+	src   rand.Source
+	img   *image.RGBA
+	fill  color.Color
+	trace *Trace
+	// last holds the pixels at the previous draw.
+	last []byte
+}
+
+func (s *traceSource) Int63() int64 {
+	s.attribute()
+	v := s.src.Int63()
+	s.trace.Draws = append(s.trace.Draws, TraceDraw{Method: "Int63", Value: strconv.FormatInt(v, 10)})
+	return v
+}
+
+// Uint64 returns the same numbers as math/rand.Rand.Uint64 on src.
+func (s *traceSource) Uint64() uint64 {
+	s.attribute()
+	var v uint64
+	if src, ok := s.src.(rand.Source64); ok {
+		v = src.Uint64()
+	} else {
+		v = uint64(s.src.Int63())>>31 | uint64(s.src.Int63())<<32
+	}
+	s.trace.Draws = append(s.trace.Draws, TraceDraw{Method: "Uint64", Value: strconv.FormatUint(v, 10)})
+	return v
+}
+
+func (s *traceSource) Seed(seed int64) {
+	s.src.Seed(seed)
+}
+
+// attribute assigns the cells that changed since the previous draw to it.
+func (s *traceSource) attribute() {
+	cells := s.changed()
+	if n := len(s.trace.Draws); n > 0 {
+		s.trace.Draws[n-1].Cells = cells
+	}
+}
+
+// changed returns the cells that changed since the previous call.
+func (s *traceSource) changed() []TraceCell {
+	var cells []TraceCell
+	for i := 0; i < len(s.img.Pix); i += 4 {
+		if string(s.img.Pix[i:i+4]) != string(s.last[i:i+4]) {
+			x, y := i%s.img.Stride/4, i/s.img.Stride
+			cells = append(cells, TraceCell{X: x, Y: y, Filled: sameColor(s.img.At(x, y), s.fill)})
+		}
+	}
+	copy(s.last, s.img.Pix)
+	return cells
+}
+
+// traceHex returns c as #rrggbbaa with premultiplied alpha.
+func traceHex(c color.Color) string {
+	r := color.RGBAModel.Convert(c).(color.RGBA)
+	return fmt.Sprintf("#%02x%02x%02x%02x", r.R, r.G, r.B, r.A)
+}
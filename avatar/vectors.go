@@ -0,0 +1,84 @@
+package avatar
+
+//go:generate go run ../internal/genvectors -out vectors.json
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Vector is a reproducibility test vector: an input value, the options it is rendered with and the
+// expected hashes of the result. Ports to other languages and downstream callers check that they
+// render byte-exact compatible avatars against the published vectors.
+type Vector struct {
+	Value   string  `json:"value"`
+	Options Options `json:"options"`
+	// OutputSHA256 is the hex encoded SHA-256 of the encoded avatar. Encoded PNGs depend on the
+	// deflate implementation, so ports that compress differently check PixelsSHA256 instead.
+	OutputSHA256 string `json:"output_sha256"`
+	// PixelsSHA256 is the hex encoded SHA-256 of the rendered pixels, four bytes of red, green, blue
+	// and alpha per pixel, row by row from the top left.
+	PixelsSHA256 string `json:"pixels_sha256"`
+}
+
+//go:embed vectors.json
+var vectorsJSON []byte
+
+var vectors = sync.OnceValue(func() []Vector {
+	var v []Vector
+	if err := json.Unmarshal(vectorsJSON, &v); err != nil {
+		panic("avatar: malformed vectors.json: " + err.Error())
+	}
+	return v
+})
+
+// Vectors returns the canonical test vectors, which are also published as the machine-readable
+// avatar/vectors.json. The vectors only change when the output of the package changes on purpose.
+func Vectors() []Vector {
+	return append([]Vector(nil), vectors()...)
+}
+
+// ComputeVector renders value with the options and returns the vector of the result. The output
+// type and directory of the options are ignored, the avatar is always encoded into memory.
+func ComputeVector(value string, o Options) (Vector, error) {
+	av := NewWithOptions(value, o)
+	av.outputType = OUTPUT_BUFFER
+	if err := av.validate(); err != nil {
+		return Vector{}, err
+	}
+	if err := av.render(); err != nil {
+		return Vector{}, err
+	}
+	pixels := sha256.Sum256(av.image.Pix)
+	data, err := av.encodeBytes()
+	if err != nil {
+		return Vector{}, err
+	}
+	output := sha256.Sum256(data)
+	return Vector{
+		Value:        value,
+		Options:      o,
+		OutputSHA256: hex.EncodeToString(output[:]),
+		PixelsSHA256: hex.EncodeToString(pixels[:]),
+	}, nil
+}
+
+// Verify renders the vector's value with its options and returns an error wrapping
+// ErrVectorMismatch if the pixels or the encoded output differ from the expected hashes.
+func (v Vector) Verify() error {
+	got, err := ComputeVector(v.Value, v.Options)
+	if err != nil {
+		return err
+	}
+	if got.PixelsSHA256 != v.PixelsSHA256 {
+		return fmt.Errorf("%w: value %q renders pixels %s, expected %s", ErrVectorMismatch, v.Value, got.PixelsSHA256, v.PixelsSHA256)
+	}
+	if got.OutputSHA256 != v.OutputSHA256 {
+		return fmt.Errorf("%w: value %q encodes to %s, expected %s", ErrVectorMismatch, v.Value, got.OutputSHA256, v.OutputSHA256)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package avatar
+
+import "testing"
+
+// TestVectors fails when the output changes, regenerate vectors.json with go generate only if the
+// change is intended.
+func TestVectors(t *testing.T) {
+	vectors := Vectors()
+	if len(vectors) == 0 {
+		t.Fatal("no vectors")
+	}
+	for _, v := range vectors {
+		if err := v.Verify(); err != nil {
+			t.Error(err)
+		}
+	}
+}
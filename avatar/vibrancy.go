@@ -0,0 +1,99 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+)
+
+// twoToneLightnessShift is the lightness difference of the shades used by WithTwoTone.
+const twoToneLightnessShift = 0.2
+
+// WithLightnessRange clamps the HSL lightness (0-1) of derived fill colors to the range from min to max,
+// whichever way they were derived, e.g. to keep avatars readable on a given background.
+func WithLightnessRange(min, max float64) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.lightnessRange = colorRange(a, min, max)
+	}
+}
+
+// WithSaturationRange clamps the HSL saturation (0-1) of derived fill colors to the range from min to max,
+// e.g. to tone down vibrant avatars without switching palettes.
+func WithSaturationRange(min, max float64) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.saturationRange = colorRange(a, min, max)
+	}
+}
+
+// WithTwoTone fills the pattern with two shades of the fill color: the derived color and a companion
+// shade, darker for light and lighter for dark colors. TWO_TONE_ALTERNATING alternates the shades
+// like a checkerboard, TWO_TONE_HALVES uses the companion shade for the right half of the pattern.
+func WithTwoTone(mode TwoTone) func(a *Avatar) {
+	return func(a *Avatar) {
+		a.twoTone = mode
+	}
+}
+
+// colorRange validates a range set by an option, recording invalid ranges in a.err.
+func colorRange(a *Avatar, min, max float64) *[2]float64 {
+	if !(0 <= min && min <= max && max <= 1) {
+		a.err = fmt.Errorf("%w: %g-%g", ErrInvalidColorRange, min, max)
+		return nil
+	}
+	return &[2]float64{min, max}
+}
+
+// clampColor clamps the lightness and saturation of c to the configured ranges, keeping its alpha.
+func (av *Avatar) clampColor(c color.Color) color.Color {
+	if av.lightnessRange == nil && av.saturationRange == nil {
+		return c
+	}
+	h, s, l := toHSL(c)
+	if r := av.saturationRange; r != nil {
+		s = math.Max(r[0], math.Min(r[1], s))
+	}
+	if r := av.lightnessRange; r != nil {
+		l = math.Max(r[0], math.Min(r[1], l))
+	}
+	clamped := fromHSL(h, s, l)
+	return color.NRGBA{clamped.R, clamped.G, clamped.B, color.NRGBAModel.Convert(c).(color.NRGBA).A}
+}
+
+// companionShade returns the lighter or darker shade of c paired with it by WithTwoTone, keeping its alpha.
+func companionShade(c color.Color) color.Color {
+	h, s, l := toHSL(c)
+	if l > 0.5 {
+		l -= twoToneLightnessShift
+	} else {
+		l += twoToneLightnessShift
+	}
+	shade := fromHSL(h, s, l)
+	return color.NRGBA{shade.R, shade.G, shade.B, color.NRGBAModel.Convert(c).(color.NRGBA).A}
+}
+
+// applyTwoTone recolors the filled cells of the pixel pattern in av.image chosen by the two tone mode.
+func (av *Avatar) applyTwoTone(hash [sha256.Size]byte, background color.Color) {
+	fill, _ := av.patternColors(hash)
+	shade := companionShade(fill)
+	size := int(av.pixelPattern)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if sameColor(av.image.At(x, y), background) {
+				continue
+			}
+			if (av.twoTone == TWO_TONE_ALTERNATING && (x+y)%2 == 1) || (av.twoTone == TWO_TONE_HALVES && 2*x >= size+1) {
+				av.image.Set(x, y, shade)
+			}
+		}
+	}
+}
+
+// rangeKey returns a string identifying a color range.
+func rangeKey(r *[2]float64) string {
+	if r == nil {
+		return "-"
+	}
+	return "-" + strconv.FormatFloat(r[0], 'g', -1, 64) + "," + strconv.FormatFloat(r[1], 'g', -1, 64)
+}
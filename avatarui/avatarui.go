@@ -0,0 +1,87 @@
+// Package avatarui adapts generated avatars to Go desktop UI toolkits and game engines.
+//
+// It does not import the toolkits themselves, so using it adds no dependencies:
+// Resource satisfies fyne.Resource, Image returns an *image.RGBA, which Gio
+// uploads without conversion, and Pixels returns the byte layout expected by
+// Ebitengine's (*ebiten.Image).WritePixels.
+//
+//	// Fyne
+//	res, err := avatarui.NewResource("jane@example.com", avatar.WithDimension(64))
+//	img := canvas.NewImageFromResource(res)
+//
+//	// Gio
+//	img, err := avatarui.Image("jane@example.com", avatar.WithDimension(64))
+//	paint.NewImageOp(img).Add(gtx.Ops)
+//	paint.PaintOp{}.Add(gtx.Ops)
+//
+//	// Ebitengine
+//	pix, w, h, err := avatarui.Pixels("npc-42", avatar.WithDimension(32))
+//	portrait := ebiten.NewImage(w, h)
+//	portrait.WritePixels(pix)
+package avatarui
+
+import (
+	"image"
+
+	"github.com/bugcacher/godenticon/avatar"
+	"github.com/bugcacher/godenticon/internal/store"
+)
+
+// Resource is a PNG encoded avatar. It implements fyne.Resource.
+type Resource struct {
+	name    string
+	content []byte
+}
+
+// NewResource generates the avatar for value as PNG. The format is always FORMAT_PNG and the output
+// type OUTPUT_BUFFER.
+func NewResource(value string, opts ...avatar.CreateOption) (*Resource, error) {
+	opts = append(opts[:len(opts):len(opts)], avatar.WithFormat(avatar.FORMAT_PNG), avatar.WithOutputType(avatar.OUTPUT_BUFFER))
+	result, err := avatar.New(value, opts...).Generate()
+	if err != nil {
+		return nil, err
+	}
+	return &Resource{name: store.FileName(value, avatar.FORMAT_PNG.Extension()), content: result.Buffer.Bytes()}, nil
+}
+
+// Name returns the file name of the avatar, which Fyne uses to identify the image format.
+func (r *Resource) Name() string {
+	return r.name
+}
+
+// Content returns the PNG encoded avatar.
+func (r *Resource) Content() []byte {
+	return r.content
+}
+
+// Image generates the avatar for value as raw pixels, e.g. for Gio's paint.NewImageOp.
+// The output type is always OUTPUT_RAW.
+func Image(value string, opts ...avatar.CreateOption) (*image.RGBA, error) {
+	opts = append(opts[:len(opts):len(opts)], avatar.WithOutputType(avatar.OUTPUT_RAW))
+	result, err := avatar.New(value, opts...).Generate()
+	if err != nil {
+		return nil, err
+	}
+	return result.Image, nil
+}
+
+// Pixels generates the avatar for value as tightly packed RGBA bytes with premultiplied alpha,
+// 4 bytes per pixel and row by row from the top left corner, together with its width and height.
+// This is the layout of (*ebiten.Image).WritePixels.
+func Pixels(value string, opts ...avatar.CreateOption) (pix []byte, width, height int, err error) {
+	img, err := Image(value, opts...)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	b := img.Bounds()
+	width, height = b.Dx(), b.Dy()
+	if img.Stride == 4*width {
+		return img.Pix[:4*width*height], width, height, nil
+	}
+	pix = make([]byte, 0, 4*width*height)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		start := img.PixOffset(b.Min.X, y)
+		pix = append(pix, img.Pix[start:start+4*width]...)
+	}
+	return pix, width, height, nil
+}
@@ -0,0 +1,22 @@
+package avatarui
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/bugcacher/godenticon/avatar"
+)
+
+func TestNewResourceIsPNG(t *testing.T) {
+	res, err := NewResource("jane@example.com", avatar.WithFormat(avatar.FORMAT_JPEG))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Name() != "jane@example.com.png" {
+		t.Errorf("got name %q", res.Name())
+	}
+	if _, err := png.Decode(bytes.NewReader(res.Content())); err != nil {
+		t.Errorf("content is not a PNG: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+// Command dedupe audits a directory of avatars generated by "godenticon warm".
+//
+// It reports files with identical images and files that no longer match what the given
+// style would generate for their value, e.g. after an algorithm change. The exit code is 1
+// if any duplicates or stale files were found.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/bugcacher/godenticon/avatar"
+	"github.com/bugcacher/godenticon/internal/store"
+)
+
+func main() {
+	dir := flag.String("dir", "avatars", "directory of generated avatars")
+	styleFile := flag.String("style", "", "JSON style file the avatars are expected to be rendered with")
+	flag.Parse()
+
+	issues, err := audit(*dir, *styleFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dedupe: %v\n", err)
+		os.Exit(2)
+	}
+	if issues > 0 {
+		os.Exit(1)
+	}
+}
+
+// audit prints the duplicate and stale files in dir and returns how many it found.
+func audit(dir, styleFile string) (int, error) {
+	var opts []avatar.CreateOption
+	if styleFile != "" {
+		style, err := avatar.LoadStyle(styleFile)
+		if err != nil {
+			return 0, err
+		}
+		opts = style.CreateOptions()
+	}
+	gen := avatar.NewGenerator(opts...)
+	extension := gen.Extension()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	issues := 0
+	byFingerprint := make(map[string][]string)
+	for _, entry := range entries {
+		value, ok := store.Value(entry.Name(), extension)
+		if entry.IsDir() || !ok {
+			continue
+		}
+		fingerprint, err := fileFingerprint(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			fmt.Printf("unreadable %s: %v\n", entry.Name(), err)
+			issues++
+			continue
+		}
+		byFingerprint[fingerprint] = append(byFingerprint[fingerprint], entry.Name())
+
+		data, err := gen.Generate(value)
+		if err != nil {
+			return issues, fmt.Errorf("%s: %w", value, err)
+		}
+		expected, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return issues, err
+		}
+		if avatar.Fingerprint(expected) != fingerprint {
+			fmt.Printf("stale %s\n", entry.Name())
+			issues++
+		}
+	}
+
+	fingerprints := make([]string, 0, len(byFingerprint))
+	for fingerprint := range byFingerprint {
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	sort.Strings(fingerprints)
+	for _, fingerprint := range fingerprints {
+		if files := byFingerprint[fingerprint]; len(files) > 1 {
+			fmt.Printf("duplicate %s: %v\n", fingerprint[:16], files)
+			issues++
+		}
+	}
+	return issues, nil
+}
+
+// fileFingerprint decodes the image at path and returns its fingerprint.
+func fileFingerprint(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return "", err
+	}
+	return avatar.Fingerprint(img), nil
+}
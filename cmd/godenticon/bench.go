@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bugcacher/godenticon/avatar"
+)
+
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	algorithmList := fs.String("algorithms", "1,2,3", "comma separated algorithms to measure")
+	sizeList := fs.String("sizes", "64,128,256,512", "comma separated dimensions in pixels to measure")
+	formatList := fs.String("formats", "png,svg,jpeg", "comma separated formats to measure")
+	duration := fs.Duration("duration", 500*time.Millisecond, "time spent measuring each case")
+	styleFile := fs.String("style", "", "JSON style file to render the avatars with")
+	fs.Parse(args)
+
+	algorithms, err := parseAlgorithms(*algorithmList)
+	if err != nil {
+		return err
+	}
+	sizes, err := parseSizes(*sizeList)
+	if err != nil {
+		return err
+	}
+	formats, err := parseFormats(*formatList)
+	if err != nil {
+		return err
+	}
+	var opts []avatar.CreateOption
+	if *styleFile != "" {
+		style, err := avatar.LoadStyle(*styleFile)
+		if err != nil {
+			return err
+		}
+		opts = style.CreateOptions()
+	}
+
+	fmt.Printf("%s/%s, %d CPUs, %s\n\n", runtime.GOOS, runtime.GOARCH, runtime.NumCPU(), runtime.Version())
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "ALGORITHM\tSIZE\tFORMAT\tRENDERS/S\tAVATARS/S\tBYTES\t")
+	for _, algorithm := range algorithms {
+		for _, size := range sizes {
+			base := append(opts[:len(opts):len(opts)], avatar.WithAlgorithm(algorithm), avatar.WithDimension(size))
+			renders, _, err := measure(*duration, append(base, avatar.WithOutputType(avatar.OUTPUT_RAW)))
+			if err != nil {
+				return err
+			}
+			for _, format := range formats {
+				avatars, bytes, err := measure(*duration, append(base, avatar.WithOutputType(avatar.OUTPUT_BUFFER), avatar.WithFormat(format)))
+				if err != nil {
+					return fmt.Errorf("%s: %w", format, err)
+				}
+				fmt.Fprintf(tw, "%d\t%d\t%s\t%.0f\t%.0f\t%d\t\n", int(algorithm)+1, size, format, renders, avatars, bytes)
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+// measure generates avatars of distinct values until d has passed and returns the avatars generated
+// per second and their average size in bytes, zero for raw output.
+func measure(d time.Duration, opts []avatar.CreateOption) (perSecond float64, bytes int, err error) {
+	var n, total int
+	start := time.Now()
+	for n == 0 || time.Since(start) < d {
+		result, err := avatar.New(strconv.Itoa(n), opts...).Generate()
+		if err != nil {
+			return 0, 0, err
+		}
+		if result.Buffer != nil {
+			total += result.Buffer.Len()
+		}
+		n++
+	}
+	return float64(n) / time.Since(start).Seconds(), total / n, nil
+}
+
+// parseAlgorithms parses a comma separated list of algorithm numbers, e.g. "1,3".
+func parseAlgorithms(list string) ([]avatar.Algorithm, error) {
+	var algorithms []avatar.Algorithm
+	for _, field := range strings.Split(list, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 1 || n > int(avatar.ALGORITHM_3)+1 {
+			return nil, fmt.Errorf("unknown algorithm %q, use 1 to %d", field, int(avatar.ALGORITHM_3)+1)
+		}
+		algorithms = append(algorithms, avatar.Algorithm(n-1))
+	}
+	return algorithms, nil
+}
+
+// parseSizes parses a comma separated list of dimensions in pixels.
+func parseSizes(list string) ([]uint, error) {
+	var sizes []uint
+	for _, field := range strings.Split(list, ",") {
+		n, err := strconv.ParseUint(strings.TrimSpace(field), 10, 0)
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("invalid size %q", field)
+		}
+		sizes = append(sizes, uint(n))
+	}
+	return sizes, nil
+}
+
+// parseFormats parses a comma separated list of format names, e.g. "png,svg".
+func parseFormats(list string) ([]avatar.Format, error) {
+	var formats []avatar.Format
+	for _, field := range strings.Split(list, ",") {
+		name := strings.TrimSpace(field)
+		format, ok := formatByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown format %q, available: %s", name, formatNames())
+		}
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+// formatByName returns the available format with the given name.
+func formatByName(name string) (avatar.Format, bool) {
+	for _, format := range avatar.Formats() {
+		if format.String() == name {
+			return format, true
+		}
+	}
+	return 0, false
+}
+
+// formatNames returns the names of the available formats, comma separated.
+func formatNames() string {
+	var names []string
+	for _, format := range avatar.Formats() {
+		names = append(names, format.String())
+	}
+	return strings.Join(names, ", ")
+}
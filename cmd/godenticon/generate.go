@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bugcacher/godenticon/avatar"
+	"github.com/bugcacher/godenticon/internal/store"
+)
+
+func runGenerate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), "Usage: godenticon generate [flags] [value ...]\n\n"+
+			"Writes the avatar of every value given with -value or as an argument to -out and prints its path.\n\n")
+		fs.PrintDefaults()
+	}
+	value := fs.String("value", "", "value to generate the avatar for, e.g. a user name or email address")
+	pattern := fs.Int("pattern", int(avatar.PIXEL_PATTERN_5), "cells per row of the pixel pattern, 5, 7, 9 or 12")
+	algo := fs.Int("algo", int(avatar.ALGORITHM_1)+1, fmt.Sprintf("algorithm filling the pattern, 1 to %d", int(avatar.ALGORITHM_3)+1))
+	dim := fs.Uint("dim", 100, "width and height of the avatar in pixels")
+	outDir := fs.String("out", ".", "directory the avatars are written to")
+	formatName := fs.String("format", "png", "image format of the avatars")
+	quality := fs.Int("quality", 0, "quality of JPEG avatars from 1 to 100, 90 if 0")
+	styleFile := fs.String("style", "", "JSON style file to render the avatars with, the other flags override it")
+	fs.Parse(args)
+
+	values := fs.Args()
+	if *value != "" {
+		values = append([]string{*value}, values...)
+	}
+	if len(values) == 0 {
+		return usageError("no value given, use -value or pass values as arguments")
+	}
+	algorithms, err := parseAlgorithms(fmt.Sprint(*algo))
+	if err != nil {
+		return usageError(err.Error())
+	}
+	switch avatar.PixelPattern(*pattern) {
+	case avatar.PIXEL_PATTERN_5, avatar.PIXEL_PATTERN_7, avatar.PIXEL_PATTERN_9, avatar.PIXEL_PATTERN_12:
+	default:
+		return usageError(fmt.Sprintf("unknown pattern %d, use 5, 7, 9 or 12", *pattern))
+	}
+	format, ok := formatByName(*formatName)
+	if !ok {
+		return usageError(fmt.Sprintf("unknown format %q, use one of %s", *formatName, formatNames()))
+	}
+
+	// Without a style every flag applies, with one only those given explicitly override it.
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	applies := func(name string) bool { return *styleFile == "" || set[name] }
+	var opts []avatar.CreateOption
+	if *styleFile != "" {
+		style, err := avatar.LoadStyle(*styleFile)
+		if err != nil {
+			return err
+		}
+		opts = style.CreateOptions()
+		if style.Format != 0 && !applies("format") {
+			format = style.Format
+		}
+	}
+	if applies("pattern") {
+		opts = append(opts, avatar.WithPixelPattern(avatar.PixelPattern(*pattern)))
+	}
+	if applies("algo") {
+		opts = append(opts, avatar.WithAlgorithm(algorithms[0]))
+	}
+	if applies("dim") {
+		opts = append(opts, avatar.WithDimension(*dim))
+	}
+	if applies("format") {
+		opts = append(opts, avatar.WithFormat(format))
+	}
+	if *quality != 0 {
+		opts = append(opts, avatar.WithJPEGQuality(*quality))
+	}
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		return err
+	}
+	opts = append(opts, avatar.WithOutputType(avatar.OUTPUT_BUFFER))
+
+	for _, value := range values {
+		result, err := avatar.New(value, opts...).Generate()
+		if err != nil {
+			return fmt.Errorf("%s: %w", value, err)
+		}
+		// Named like the files of warm, so every value gets a file of its own.
+		path := filepath.Join(*outDir, store.FileName(value, format.Extension()))
+		if err := os.WriteFile(path, result.Buffer.Bytes(), 0644); err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Command godenticon generates GitHub-like identicons from the command line.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: godenticon <command> [flags]
+
+Commands:
+  generate  write the avatars of values to a directory
+  warm      pre-generate avatars for a list of values or a numeric ID range
+  trace     print the derivation of a value's pattern and colors as JSON
+  bench     measure rendering and encoding throughput on this machine
+
+Run "godenticon <command> -h" for the flags of a command.
+
+The exit status is 0 on success, 1 if a command fails and 2 for invalid usage.
+`
+
+// usageError is returned by commands invoked with invalid flags or arguments.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "generate":
+		err = runGenerate(os.Args[2:])
+	case "warm":
+		err = runWarm(os.Args[2:])
+	case "trace":
+		err = runTrace(os.Args[2:])
+	case "bench":
+		err = runBench(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "godenticon: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "godenticon %s: %v\n", os.Args[1], err)
+		if errors.As(err, new(usageError)) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
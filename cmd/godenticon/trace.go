@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/bugcacher/godenticon/avatar"
+)
+
+func runTrace(args []string) error {
+	fs := flag.NewFlagSet("trace", flag.ExitOnError)
+	styleFile := fs.String("style", "", "JSON style file to derive the avatar with")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("expected exactly one value")
+	}
+	var opts []avatar.CreateOption
+	if *styleFile != "" {
+		style, err := avatar.LoadStyle(*styleFile)
+		if err != nil {
+			return err
+		}
+		opts = style.CreateOptions()
+	}
+
+	trace, err := avatar.New(fs.Arg(0), opts...).Trace()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(trace)
+}
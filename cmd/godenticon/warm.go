@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bugcacher/godenticon/avatar"
+	"github.com/bugcacher/godenticon/internal/store"
+)
+
+func runWarm(args []string) error {
+	fs := flag.NewFlagSet("warm", flag.ExitOnError)
+	valuesFile := fs.String("values", "", "file with one value per line")
+	idRange := fs.String("range", "", "numeric ID range to generate, e.g. 1-1000")
+	outDir := fs.String("out", "avatars", "directory the avatars are written to")
+	styleFile := fs.String("style", "", "JSON style file to render the avatars with")
+	manifestFile := fs.String("manifest", "", "file a manifest of the written avatars is written to")
+	manifestFormat := fs.String("manifest-format", "json", "format of the manifest, json or csv")
+	incremental := fs.Bool("incremental", false, "skip avatars the previous -manifest lists with the same style")
+	fs.Parse(args)
+
+	var values []string
+	if *valuesFile != "" {
+		var err error
+		if values, err = store.ReadValues(*valuesFile); err != nil {
+			return err
+		}
+	}
+	var first, last uint64
+	if *idRange != "" {
+		var err error
+		if first, last, err = parseRange(*idRange); err != nil {
+			return err
+		}
+	} else if len(values) == 0 {
+		return errors.New("no values given, use -values or -range")
+	}
+
+	var opts []avatar.CreateOption
+	if *styleFile != "" {
+		style, err := avatar.LoadStyle(*styleFile)
+		if err != nil {
+			return err
+		}
+		opts = style.CreateOptions()
+	}
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		return err
+	}
+
+	if *incremental && *manifestFile == "" {
+		return errors.New("-incremental requires -manifest")
+	}
+	gen := avatar.NewGenerator(opts...)
+	extension := gen.Extension()
+	style := gen.Style()
+
+	var manifest *avatar.Manifest
+	var manifestTmp *os.File
+	previous := make(map[string]avatar.ManifestEntry)
+	if *manifestFile != "" {
+		format, err := parseManifestFormat(*manifestFormat)
+		if err != nil {
+			return err
+		}
+		if *incremental {
+			if previous, err = readManifest(*manifestFile, format); err != nil {
+				return err
+			}
+		}
+		// The manifest is written next to the previous one, which it only replaces once all
+		// avatars are warmed, so a failed run keeps the entries of the previous run.
+		if manifestTmp, err = os.CreateTemp(filepath.Dir(*manifestFile), ".manifest-*"); err != nil {
+			return err
+		}
+		defer func() {
+			manifestTmp.Close()
+			os.Remove(manifestTmp.Name())
+		}()
+		manifest = avatar.NewManifest(manifestTmp, format)
+	}
+
+	warmed, skipped := 0, 0
+	warm := func(value string) error {
+		path := filepath.Join(*outDir, store.FileName(value, extension))
+		if entry, ok := previous[value]; ok && upToDate(entry, style, path) {
+			skipped++
+			return manifest.Write(entry)
+		}
+		data, err := gen.Generate(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", value, err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+		warmed++
+		if manifest == nil {
+			return nil
+		}
+		entry, err := avatar.NewManifestEntry(value, style, path, data)
+		if err != nil {
+			return err
+		}
+		return manifest.Write(entry)
+	}
+	for _, value := range values {
+		if err := warm(value); err != nil {
+			return err
+		}
+	}
+	// IDs are generated one at a time, so large ranges are not held in memory.
+	if *idRange != "" {
+		for id := first; ; id++ {
+			if err := warm(strconv.FormatUint(id, 10)); err != nil {
+				return err
+			}
+			if id == last {
+				break
+			}
+		}
+	}
+	if manifestTmp != nil {
+		if err := replaceFile(manifestTmp, *manifestFile); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("warmed %d avatars in %s, %d unchanged\n", warmed, *outDir, skipped)
+	return nil
+}
+
+// replaceFile closes the written temporary file tmp and renames it to path, replacing the file at path.
+func replaceFile(tmp *os.File, path string) error {
+	if err := tmp.Chmod(0644); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// readManifest returns the entries of the manifest at path by value, or none if it does not exist yet.
+func readManifest(path string, format avatar.ManifestFormat) (map[string]avatar.ManifestEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]avatar.ManifestEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := avatar.ReadManifest(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	byValue := make(map[string]avatar.ManifestEntry, len(entries))
+	for _, entry := range entries {
+		byValue[entry.Value] = entry
+	}
+	return byValue, nil
+}
+
+// upToDate reports whether the avatar recorded by entry was rendered in style and is still stored at path.
+func upToDate(entry avatar.ManifestEntry, style, path string) bool {
+	if entry.Style != style || entry.Path != path {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Size() == int64(entry.Bytes)
+}
+
+// parseManifestFormat returns the manifest format with the given name.
+func parseManifestFormat(name string) (avatar.ManifestFormat, error) {
+	switch name {
+	case "json":
+		return avatar.MANIFEST_JSON, nil
+	case "csv":
+		return avatar.MANIFEST_CSV, nil
+	}
+	return 0, fmt.Errorf("unknown manifest format %q, use json or csv", name)
+}
+
+// parseRange returns the first and last ID of a numeric ID range like "1-1000".
+func parseRange(idRange string) (first, last uint64, err error) {
+	from, to, ok := strings.Cut(idRange, "-")
+	first, err1 := strconv.ParseUint(from, 10, 64)
+	last, err2 := strconv.ParseUint(to, 10, 64)
+	if !ok || err1 != nil || err2 != nil || first > last {
+		return 0, 0, fmt.Errorf("invalid range %q", idRange)
+	}
+	return first, last, nil
+}
@@ -0,0 +1,80 @@
+// Command migrate regenerates the avatars of a list of values under a new style profile.
+//
+// The avatars are written into a new directory using the layout of "godenticon warm", so the
+// previous avatars stay untouched until the new directory is switched in. With -dry-run the
+// avatars are rendered but not written.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bugcacher/godenticon/avatar"
+	"github.com/bugcacher/godenticon/internal/store"
+)
+
+func main() {
+	valuesFile := flag.String("values", "", "file with one value per line")
+	styleFile := flag.String("style", "", "JSON style file of the new style profile")
+	outDir := flag.String("out", "", "directory the regenerated avatars are written to")
+	dryRun := flag.Bool("dry-run", false, "render the avatars without writing them")
+	every := flag.Duration("progress", time.Second, "interval between progress reports")
+	flag.Parse()
+
+	if err := migrate(*valuesFile, *styleFile, *outDir, *dryRun, *every); err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func migrate(valuesFile, styleFile, outDir string, dryRun bool, every time.Duration) error {
+	if valuesFile == "" || styleFile == "" || outDir == "" {
+		return errors.New("-values, -style and -out are required")
+	}
+	values, err := store.ReadValues(valuesFile)
+	if err != nil {
+		return err
+	}
+	style, err := avatar.LoadStyle(styleFile)
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			return err
+		}
+	}
+
+	gen := avatar.NewGenerator(style.CreateOptions()...)
+	extension := gen.Extension()
+	start, lastReport := time.Now(), time.Now()
+	failed := 0
+	for i, value := range values {
+		data, err := gen.Generate(value)
+		if err == nil && !dryRun {
+			err = os.WriteFile(filepath.Join(outDir, store.FileName(value, extension)), data, 0644)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", value, err)
+			failed++
+		}
+		if time.Since(lastReport) >= every {
+			fmt.Fprintf(os.Stderr, "%d/%d avatars (%.0f%%)\n", i+1, len(values), float64(i+1)*100/float64(len(values)))
+			lastReport = time.Now()
+		}
+	}
+
+	verb := "migrated"
+	if dryRun {
+		verb = "would migrate"
+	}
+	fmt.Printf("%s %d avatars to %s in %s, %d failed\n", verb, len(values)-failed, outDir, time.Since(start).Round(time.Millisecond), failed)
+	if failed > 0 {
+		return fmt.Errorf("%d avatars failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package corpus produces a deterministic corpus of avatar input values covering edge cases,
+// for the tests of this module and for integration tests of downstream code.
+package corpus
+
+//go:generate go run ../internal/gencorpus -out ../testdata/corpus.txt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// EdgeCases returns values that commonly trip up hashing and rendering: empty and whitespace only
+// strings, numeric look-alikes, very long strings, unicode, invalid UTF-8 and binary data.
+func EdgeCases() []string {
+	binary := make([]byte, 256)
+	for i := range binary {
+		binary[i] = byte(i)
+	}
+	return []string{
+		"",
+		" ",
+		"\t\n",
+		"a",
+		"A",
+		"0",
+		"42",
+		"042",
+		"-1",
+		"18446744073709551615",
+		"user@example.com",
+		"User@Example.COM",
+		strings.Repeat("a", 1024),
+		strings.Repeat("0123456789", 1000),
+		"h\u00e9llo",
+		"he\u0301llo",
+		"日本語",
+		"שלום",
+		"\U0001F600\U0001F469\u200d\U0001F469\u200d\U0001F467",
+		"a\u200bb",
+		"\x00",
+		"\xff\xfe\xfd",
+		string(binary),
+	}
+}
+
+// Random returns n pseudo random values derived from seed. The same seed always yields the same values.
+func Random(seed int64, n int) []string {
+	alphabets := []string{
+		"abcdefghijklmnopqrstuvwxyz",
+		"ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.@",
+		"äöüßéèçñøåæœ",
+		"あいうえおカキクケコ漢字",
+	}
+	r := rand.New(rand.NewSource(seed))
+	values := make([]string, n)
+	for i := range values {
+		var value strings.Builder
+		length := 1 + r.Intn(64)
+		for j := 0; j < length; j++ {
+			if r.Intn(16) == 0 {
+				value.WriteByte(byte(r.Intn(256)))
+				continue
+			}
+			alphabet := []rune(alphabets[r.Intn(len(alphabets))])
+			value.WriteRune(alphabet[r.Intn(len(alphabet))])
+		}
+		values[i] = value.String()
+	}
+	return values
+}
+
+// Values returns the edge cases followed by n random values derived from seed.
+func Values(seed int64, n int) []string {
+	return append(EdgeCases(), Random(seed, n)...)
+}
+
+// Write writes the values to w, one Go quoted string per line.
+func Write(w io.Writer, values []string) error {
+	for _, value := range values {
+		if _, err := fmt.Fprintln(w, strconv.Quote(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read reads values written by Write.
+func Read(r io.Reader) ([]string, error) {
+	var values []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		value, err := strconv.Unquote(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", len(values)+1, err)
+		}
+		values = append(values, value)
+	}
+	return values, scanner.Err()
+}
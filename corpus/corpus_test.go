@@ -0,0 +1,42 @@
+package corpus
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestCorpusFile checks that testdata/corpus.txt is the output of go generate, which runs
+// internal/gencorpus with its default seed of 1 and 100 random values.
+func TestCorpusFile(t *testing.T) {
+	want, err := os.ReadFile("../testdata/corpus.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if err := Write(&got, Values(1, 100)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Error("testdata/corpus.txt is outdated, run go generate ./corpus")
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	values := Values(7, 20)
+	var buf bytes.Buffer
+	if err := Write(&buf, values); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, values) {
+		t.Error("values read differ from the values written")
+	}
+	if !reflect.DeepEqual(Random(7, 20), Random(7, 20)) {
+		t.Error("Random is not deterministic")
+	}
+}
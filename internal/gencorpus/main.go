@@ -0,0 +1,40 @@
+// Command gencorpus writes the deterministic test corpus of package corpus to a file.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bugcacher/godenticon/corpus"
+)
+
+func main() {
+	out := flag.String("out", "testdata/corpus.txt", "file the corpus is written to")
+	seed := flag.Int64("seed", 1, "seed of the random values")
+	n := flag.Int("n", 100, "number of random values")
+	flag.Parse()
+
+	if err := write(*out, corpus.Values(*seed, *n)); err != nil {
+		fmt.Fprintf(os.Stderr, "gencorpus: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func write(path string, values []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err := corpus.Write(w, values); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
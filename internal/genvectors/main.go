@@ -0,0 +1,118 @@
+// Command genvectors writes the reproducibility test vectors of package avatar to a file.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bugcacher/godenticon/avatar"
+)
+
+// vectorCase is an input of a test vector.
+type vectorCase struct {
+	value string
+	opts  avatar.Options
+}
+
+// cases cover every pattern size, algorithm and PRNG and the options that change the rendered pixels
+// or the encoding, each on its own so a mismatch points to the option.
+func cases() []vectorCase {
+	split := [2]int{64, 32}
+	safeArea := 80.0
+	return []vectorCase{
+		{"user@example.com", avatar.Options{}},
+		{"", avatar.Options{EmptyValuePolicy: avatar.EMPTY_VALUE_HASH}},
+		{"日本語", avatar.Options{}},
+		{"user@example.com", avatar.Options{PixelPattern: avatar.PIXEL_PATTERN_7}},
+		{"42", avatar.Options{PixelPattern: avatar.PIXEL_PATTERN_9, Algorithm: avatar.ALGORITHM_2}},
+		{"42", avatar.Options{PixelPattern: avatar.PIXEL_PATTERN_12, Algorithm: avatar.ALGORITHM_3}},
+		{"jane", avatar.Options{PRNG: avatar.PRNG_XOSHIRO}},
+		{"jane", avatar.Options{PRNG: avatar.PRNG_PCG}},
+		{"jane", avatar.Options{PRNG: avatar.PRNG_CHACHA8}},
+		{"jane", avatar.Options{EntropySplit: &split}},
+		{"jane", avatar.Options{SecurityProfile: true}},
+		{"j\u0430ne", avatar.Options{SanitizeValue: true}},
+		{"jane", avatar.Options{DarkMode: true}},
+		{"jane", avatar.Options{Palette: avatar.PaletteNames()[0]}},
+		{"jane", avatar.Options{BrandColor: "#3366cc"}},
+		{"jane", avatar.Options{ColorSpace: avatar.COLOR_SPACE_OKLCH}},
+		{"jane", avatar.Options{ColorSpace: avatar.COLOR_SPACE_CIELCH}},
+		{"jane", avatar.Options{TwoTone: avatar.TWO_TONE_ALTERNATING}},
+		{"jane", avatar.Options{TwoTone: avatar.TWO_TONE_HALVES}},
+		{"jane", avatar.Options{GradientBackground: true}},
+		{"jane", avatar.Options{PlaceholderPhoto: true}},
+		{"jane", avatar.Options{CellBevel: 0.3}},
+		{"jane", avatar.Options{SafeArea: &safeArea}},
+		{"jane", avatar.Options{Dimension: 64, ScaleFactor: 2}},
+		{"jane", avatar.Options{PixelPattern: avatar.PIXEL_PATTERN_7, SnapToGrid: true}},
+		{"jane", avatar.Options{Supersampling: 2}},
+		{"jane", avatar.Options{Format: avatar.FORMAT_SVG}},
+		{"jane", avatar.Options{Format: avatar.FORMAT_HTML}},
+		{"jane", avatar.Options{Format: avatar.FORMAT_JPEG, JPEGQuality: 75}},
+	}
+}
+
+func main() {
+	out := flag.String("out", "avatar/vectors.json", "file the vectors are written to")
+	flag.Parse()
+
+	if err := write(*out); err != nil {
+		fmt.Fprintf(os.Stderr, "genvectors: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func write(path string) error {
+	type vector struct {
+		Value        string         `json:"value"`
+		Options      map[string]any `json:"options"`
+		OutputSHA256 string         `json:"output_sha256"`
+		PixelsSHA256 string         `json:"pixels_sha256"`
+	}
+	var vectors []vector
+	for _, c := range cases() {
+		v, err := avatar.ComputeVector(c.value, c.opts)
+		if err != nil {
+			return err
+		}
+		opts, err := nonZero(c.opts)
+		if err != nil {
+			return err
+		}
+		vectors = append(vectors, vector{v.Value, opts, v.OutputSHA256, v.PixelsSHA256})
+	}
+	data, err := json.MarshalIndent(vectors, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// nonZero returns the JSON fields of the options that are set, which keeps the file readable.
+func nonZero(o avatar.Options) (map[string]any, error) {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range fields {
+		switch value := value.(type) {
+		case nil:
+			delete(fields, name)
+		case bool, float64, string:
+			if value == false || value == 0.0 || value == "" {
+				delete(fields, name)
+			}
+		case []any:
+			if len(value) == 0 {
+				delete(fields, name)
+			}
+		}
+	}
+	return fields, nil
+}
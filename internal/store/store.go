@@ -0,0 +1,47 @@
+// Package store defines how the command line tools lay out avatars in an output directory.
+package store
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// FileName returns the name of the file the avatar for value is stored in, with the file extension
+// of the format the avatar is encoded in, e.g. ".png" from avatar.Format.Extension.
+func FileName(value, extension string) string {
+	return url.PathEscape(value) + extension
+}
+
+// Value returns the value whose avatar is stored in the file with the given name.
+// It reports false for names that were not produced by FileName with the same extension.
+func Value(fileName, extension string) (string, bool) {
+	name, ok := strings.CutSuffix(fileName, extension)
+	if !ok {
+		return "", false
+	}
+	value, err := url.PathUnescape(name)
+	if err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+// ReadValues reads the non-empty lines of the file at path, with surrounding whitespace removed.
+func ReadValues(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var values []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			values = append(values, line)
+		}
+	}
+	return values, scanner.Err()
+}
@@ -0,0 +1,15 @@
+package store
+
+import "testing"
+
+func TestFileName(t *testing.T) {
+	for _, value := range []string{"jane@example.com", "a/b c", "42"} {
+		name := FileName(value, ".jpg")
+		if got, ok := Value(name, ".jpg"); !ok || got != value {
+			t.Errorf("Value(%q) = %q, %t, want %q", name, got, ok, value)
+		}
+		if _, ok := Value(name, ".png"); ok {
+			t.Errorf("Value(%q) matched another extension", name)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Package rediscache implements avatar.ByteCache on Redis, so a fleet of avatar servers shares
+// generated avatars. It speaks the Redis protocol directly and adds no dependencies:
+//
+//	cache := rediscache.New("localhost:6379")
+//	gen := avatar.NewGenerator(avatar.WithByteCache(cache, 24*time.Hour))
+package rediscache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bugcacher/godenticon/avatar"
+)
+
+// maxIdle is the number of idle connections kept for reuse.
+const maxIdle = 8
+
+// defaultTimeout bounds dialing and every command unless Cache.Timeout is set.
+const defaultTimeout = time.Second
+
+var _ avatar.ByteCache = (*Cache)(nil)
+
+// Cache is an avatar.ByteCache storing avatars in a Redis server. It is safe for concurrent use.
+type Cache struct {
+	addr string
+	// Password, when set, authenticates new connections.
+	Password string
+	// DB selects the database of new connections, 0 by default.
+	DB int
+	// Timeout bounds dialing and every command, one second if zero.
+	Timeout time.Duration
+
+	mu   sync.Mutex
+	idle []*conn
+}
+
+// conn is a connection to the Redis server.
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// New creates a Cache for the Redis server at addr, e.g. "localhost:6379". Connections are
+// opened on first use, so the fields of the Cache may be set until then.
+func New(addr string) *Cache {
+	return &Cache{addr: addr}
+}
+
+// Get returns the data stored for key and whether it was found.
+func (c *Cache) Get(key string) ([]byte, bool, error) {
+	reply, err := c.do("GET", key)
+	if err != nil {
+		return nil, false, err
+	}
+	data, ok := reply.([]byte)
+	return data, ok, nil
+}
+
+// Set stores data for key, expiring after ttl unless ttl is zero.
+func (c *Cache) Set(key string, data []byte, ttl time.Duration) error {
+	args := []any{"SET", key, data}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(max(1, ttl.Milliseconds()), 10))
+	}
+	_, err := c.do(args...)
+	return err
+}
+
+// Close closes the idle connections. The Cache opens new connections when it is used again.
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	idle := c.idle
+	c.idle = nil
+	c.mu.Unlock()
+	var errs []error
+	for _, cn := range idle {
+		errs = append(errs, cn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// do sends a command of string and byte slice arguments and returns its reply. Connections are
+// reused after error replies of the server and closed after any other error.
+func (c *Cache) do(args ...any) (any, error) {
+	cn, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := cn.do(c.timeout(), args...)
+	if err != nil {
+		var redisErr Error
+		if !errors.As(err, &redisErr) {
+			cn.Close()
+			return nil, err
+		}
+	}
+	c.put(cn)
+	return reply, err
+}
+
+func (c *Cache) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return defaultTimeout
+}
+
+// get returns an idle connection, or dials a new one.
+func (c *Cache) get() (*conn, error) {
+	c.mu.Lock()
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+
+	nc, err := net.DialTimeout("tcp", c.addr, c.timeout())
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc)}
+	if c.Password != "" {
+		if _, err := cn.do(c.timeout(), "AUTH", c.Password); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	if c.DB != 0 {
+		if _, err := cn.do(c.timeout(), "SELECT", strconv.Itoa(c.DB)); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+// put returns a healthy connection for reuse.
+func (c *Cache) put(cn *conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.idle) < maxIdle {
+		c.idle = append(c.idle, cn)
+		return
+	}
+	cn.Close()
+}
+
+// Error is an error reply of the Redis server.
+type Error string
+
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+// do writes a command and reads its reply within timeout.
+func (cn *conn) do(timeout time.Duration, args ...any) (any, error) {
+	cmd, err := appendCommand(nil, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := cn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	if _, err := cn.Write(cmd); err != nil {
+		return nil, err
+	}
+	return cn.readReply()
+}
+
+// appendCommand appends a command of string and byte slice arguments in the Redis protocol to b.
+// Other arguments return an error before anything is appended to the connection.
+func appendCommand(b []byte, args []any) ([]byte, error) {
+	b = append(strconv.AppendInt(append(b, '*'), int64(len(args)), 10), "\r\n"...)
+	for _, arg := range args {
+		var data []byte
+		switch arg := arg.(type) {
+		case string:
+			data = []byte(arg)
+		case []byte:
+			data = arg
+		default:
+			return nil, fmt.Errorf("redis: unsupported argument type %T", arg)
+		}
+		b = append(strconv.AppendInt(append(b, '$'), int64(len(data)), 10), "\r\n"...)
+		b = append(append(b, data...), "\r\n"...)
+	}
+	return b, nil
+}
+
+// readReply reads a reply of the Redis protocol: simple strings and integers as strings, bulk
+// strings as byte slices and nil bulk strings as nil. Arrays are not used by the cache.
+func (cn *conn) readReply() (any, error) {
+	line, err := cn.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+', ':':
+		return payload, nil
+	case '-':
+		return nil, Error(payload)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed bulk length %q", payload)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(cn.r, data); err != nil {
+			return nil, err
+		}
+		if string(data[n:]) != "\r\n" {
+			return nil, fmt.Errorf("redis: malformed bulk string of %d bytes", n)
+		}
+		return data[:n], nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
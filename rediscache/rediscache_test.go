@@ -0,0 +1,254 @@
+package rediscache
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer is a Redis server on a loopback listener that keeps values in memory and records the
+// commands it receives. GET of the key "fail" returns an error reply.
+type fakeServer struct {
+	ln       net.Listener
+	password string
+
+	mu       sync.Mutex
+	values   map[string][]byte
+	commands []string
+	conns    int
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{ln: ln, values: make(map[string][]byte)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+			go s.serve(nc)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) serve(nc net.Conn) {
+	defer nc.Close()
+	r := bufio.NewReader(nc)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, strings.Join(args, " "))
+		var reply string
+		switch strings.ToUpper(args[0]) {
+		case "AUTH":
+			reply = "+OK\r\n"
+			if args[1] != s.password {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case "SELECT":
+			reply = "+OK\r\n"
+		case "SET":
+			s.values[args[1]] = []byte(args[2])
+			reply = "+OK\r\n"
+		case "GET":
+			data, ok := s.values[args[1]]
+			switch {
+			case args[1] == "fail":
+				reply = "-ERR fail\r\n"
+			case !ok:
+				reply = "$-1\r\n"
+			default:
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(data), data)
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		s.mu.Unlock()
+		if _, err := io.WriteString(nc, reply); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	var n int
+	if _, err := fmt.Fscanf(r, "*%d\r\n", &n); err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		var size int
+		if _, err := fmt.Fscanf(r, "$%d\r\n", &size); err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func (s *fakeServer) stats() (commands []string, conns int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...), s.conns
+}
+
+func TestGetSet(t *testing.T) {
+	s := newFakeServer(t)
+	c := New(s.ln.Addr().String())
+	defer c.Close()
+
+	if data, ok, err := c.Get("missing"); err != nil || ok || data != nil {
+		t.Errorf("Get of a missing key: got %q, %v, %v", data, ok, err)
+	}
+	want := []byte("\x89PNG\r\n\x1a\n")
+	if err := c.Set("avatar", want, 1500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if data, ok, err := c.Get("avatar"); err != nil || !ok || !bytes.Equal(data, want) {
+		t.Errorf("Get: got %q, %v, %v", data, ok, err)
+	}
+	commands, conns := s.stats()
+	if set := commands[1]; set != "SET avatar "+string(want)+" PX 1500" {
+		t.Errorf("got command %q", set)
+	}
+	if conns != 1 {
+		t.Errorf("got %d connections, want 1", conns)
+	}
+}
+
+func TestErrorReplyKeepsConnection(t *testing.T) {
+	s := newFakeServer(t)
+	c := New(s.ln.Addr().String())
+	defer c.Close()
+
+	var redisErr Error
+	if _, _, err := c.Get("fail"); !errors.As(err, &redisErr) || redisErr != "ERR fail" {
+		t.Fatalf("got %v, want the error reply", err)
+	}
+	if _, _, err := c.Get("missing"); err != nil {
+		t.Fatal(err)
+	}
+	if _, conns := s.stats(); conns != 1 {
+		t.Errorf("got %d connections, want the connection reused after the error reply", conns)
+	}
+}
+
+func TestAuthSelect(t *testing.T) {
+	s := newFakeServer(t)
+	s.password = "secret"
+	c := New(s.ln.Addr().String())
+	c.Password, c.DB = "secret", 2
+	defer c.Close()
+
+	if _, _, err := c.Get("missing"); err != nil {
+		t.Fatal(err)
+	}
+	commands, _ := s.stats()
+	if got := strings.Join(commands, ", "); got != "AUTH secret, SELECT 2, GET missing" {
+		t.Errorf("got commands %q", got)
+	}
+
+	c = New(s.ln.Addr().String())
+	c.Password = "wrong"
+	var redisErr Error
+	if _, _, err := c.Get("missing"); !errors.As(err, &redisErr) {
+		t.Errorf("wrong password: got %v, want the error reply", err)
+	}
+	if len(c.idle) != 0 {
+		t.Error("connection that failed to authenticate was kept")
+	}
+}
+
+func TestUnsupportedArgument(t *testing.T) {
+	s := newFakeServer(t)
+	c := New(s.ln.Addr().String())
+	defer c.Close()
+
+	if _, err := c.do("SET", "key", 42); err == nil {
+		t.Fatal("got no error for an int argument")
+	}
+	if _, _, err := c.Get("missing"); err != nil {
+		t.Fatal(err)
+	}
+	if commands, _ := s.stats(); len(commands) != 1 || commands[0] != "GET missing" {
+		t.Errorf("got commands %q, want only the GET", commands)
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	client, server := net.Pipe()
+	server.Close()
+	cn := &conn{Conn: client, r: bufio.NewReader(client)}
+	if _, err := cn.do(time.Second, "GET", "key"); err == nil {
+		t.Error("got no error writing to a closed connection")
+	}
+}
+
+func TestReadReply(t *testing.T) {
+	for _, tc := range []struct {
+		reply string
+		want  any
+		err   bool
+	}{
+		{"+OK\r\n", "OK", false},
+		{":42\r\n", "42", false},
+		{"$5\r\nhello\r\n", []byte("hello"), false},
+		{"$0\r\n\r\n", []byte{}, false},
+		{"$-1\r\n", nil, false},
+		{"-ERR boom\r\n", nil, true},
+		{"$5\r\nhelloXX", nil, true},
+		{"$x\r\n", nil, true},
+		{"*1\r\n", nil, true},
+		{"+OK\n", nil, true},
+		{"$5\r\nhel", nil, true},
+	} {
+		cn := &conn{r: bufio.NewReader(strings.NewReader(tc.reply))}
+		got, err := cn.readReply()
+		if (err != nil) != tc.err {
+			t.Errorf("%q: got error %v", tc.reply, err)
+			continue
+		}
+		if fmt.Sprintf("%T %v", got, got) != fmt.Sprintf("%T %v", tc.want, tc.want) {
+			t.Errorf("%q: got %T %v, want %T %v", tc.reply, got, got, tc.want, tc.want)
+		}
+	}
+	cn := &conn{r: bufio.NewReader(strings.NewReader("-ERR boom\r\n"))}
+	if _, err := cn.readReply(); err != Error("ERR boom") {
+		t.Errorf("got %v, want Error(%q)", err, "ERR boom")
+	}
+}
+
+func TestAppendCommand(t *testing.T) {
+	got, err := appendCommand(nil, []any{"SET", "k", []byte("v"), "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "*4\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n$1\r\n1\r\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}